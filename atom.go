@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/xml"
+	"strings"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type atomFeed struct {
+	Lang      string      `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Title     atomText    `xml:"title"`
+	Subtitle  atomText    `xml:"subtitle"`
+	Links     []atomLink  `xml:"link"`
+	Icon      string      `xml:"icon"`
+	Logo      string      `xml:"logo"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     atomText   `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   atomText   `xml:"summary"`
+	Content   atomText   `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+}
+
+// atomText holds a text construct, xhtml content keeps its markup
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Text  string `xml:",chardata"`
+	Inner string `xml:",innerxml"`
+}
+
+func (t atomText) String() string {
+	if t.Type == "xhtml" {
+		return strings.TrimSpace(t.Inner)
+	}
+	return strings.TrimSpace(t.Text)
+}
+
+// alternateLink returns the rel="alternate" link, a link without rel means alternate
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return link.Href
+		}
+	}
+
+	if len(links) > 0 {
+		return links[0].Href
+	}
+
+	return ""
+}
+
+func parseAtom(body []byte) (*RSSFeed, error) {
+	var atom atomFeed
+	if err := xml.Unmarshal(body, &atom); err != nil {
+		return nil, err
+	}
+
+	var feed RSSFeed
+	feed.Channel.Title = atom.Title.String()
+	feed.Channel.Link = alternateLink(atom.Links)
+	feed.Channel.Description = atom.Subtitle.String()
+	feed.Channel.Language = strings.TrimSpace(atom.Lang)
+	feed.Channel.Generator = strings.TrimSpace(atom.Generator)
+
+	// the logo is the larger image, the icon is a favicon
+	feed.Channel.Image = strings.TrimSpace(atom.Logo)
+	if feed.Channel.Image == "" {
+		feed.Channel.Image = strings.TrimSpace(atom.Icon)
+	}
+
+	for _, entry := range atom.Entries {
+		description := entry.Summary.String()
+		if description == "" {
+			description = entry.Content.String()
+		}
+
+		pubDate := entry.Published
+		if pubDate == "" {
+			pubDate = entry.Updated
+		}
+
+		feed.Channel.Item = append(feed.Channel.Item, RSSItem{
+			GUID:        strings.TrimSpace(entry.ID),
+			Title:       entry.Title.String(),
+			Link:        alternateLink(entry.Links),
+			Description: description,
+			PubDate:     strings.TrimSpace(pubDate),
+		})
+	}
+
+	return &feed, nil
+}
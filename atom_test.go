@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const atomFixture = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xml:lang="en-us">
+  <title>Example Atom</title>
+  <subtitle type="html">A &lt;b&gt;test&lt;/b&gt; feed</subtitle>
+  <link rel="self" href="https://example.com/atom.xml"/>
+  <link rel="alternate" href="https://example.com/"/>
+  <entry>
+    <id>urn:uuid:1</id>
+    <title>Summary and content</title>
+    <link rel="edit" href="https://example.com/edit/1"/>
+    <link rel="alternate" href="https://example.com/1"/>
+    <summary>The summary</summary>
+    <content type="html">The content</content>
+    <published>2024-01-02T03:04:05Z</published>
+    <updated>2024-02-02T03:04:05Z</updated>
+  </entry>
+  <entry>
+    <id>urn:uuid:2</id>
+    <title type="text">  Content only  </title>
+    <link href="https://example.com/2"/>
+    <content type="xhtml"><div xmlns="http://www.w3.org/1999/xhtml"><p>Hi</p></div></content>
+    <updated>2024-02-03T00:00:00Z</updated>
+  </entry>
+  <entry>
+    <id>urn:uuid:3</id>
+    <title>Only other links</title>
+    <link rel="replies" href="https://example.com/3/comments"/>
+    <link rel="related" href="https://example.com/3/related"/>
+  </entry>
+</feed>`
+
+func TestParseAtom(t *testing.T) {
+	feed, err := parseFeed([]byte(atomFixture), "application/atom+xml")
+	if err != nil {
+		t.Fatalf("parseFeed() error: %v", err)
+	}
+
+	if feed.Channel.Title != "Example Atom" {
+		t.Errorf("title = %q, want %q", feed.Channel.Title, "Example Atom")
+	}
+	if feed.Channel.Link != "https://example.com/" {
+		t.Errorf("link = %q, want the alternate link, not self", feed.Channel.Link)
+	}
+	if feed.Channel.Description != "A <b>test</b> feed" {
+		t.Errorf("description = %q, want %q", feed.Channel.Description, "A <b>test</b> feed")
+	}
+	if feed.Channel.Language != "en-us" {
+		t.Errorf("language = %q, want %q", feed.Channel.Language, "en-us")
+	}
+
+	want := []RSSItem{
+		{GUID: "urn:uuid:1", Title: "Summary and content", Link: "https://example.com/1", Description: "The summary", PubDate: "2024-01-02T03:04:05Z"},
+		{GUID: "urn:uuid:2", Title: "Content only", Link: "https://example.com/2", Description: `<div xmlns="http://www.w3.org/1999/xhtml"><p>Hi</p></div>`, PubDate: "2024-02-03T00:00:00Z"},
+		{GUID: "urn:uuid:3", Title: "Only other links", Link: "https://example.com/3/comments"},
+	}
+	if !reflect.DeepEqual(feed.Channel.Item, want) {
+		t.Errorf("items =\n%+v\nwant\n%+v", feed.Channel.Item, want)
+	}
+}
+
+func TestAlternateLink(t *testing.T) {
+	tests := []struct {
+		name  string
+		links []atomLink
+		want  string
+	}{
+		{"no links", nil, ""},
+		{"alternate after self", []atomLink{{Href: "self", Rel: "self"}, {Href: "alternate", Rel: "alternate"}}, "alternate"},
+		{"missing rel means alternate", []atomLink{{Href: "enclosure", Rel: "enclosure"}, {Href: "plain"}}, "plain"},
+		{"first link without an alternate", []atomLink{{Href: "first", Rel: "related"}, {Href: "second", Rel: "via"}}, "first"},
+	}
+
+	for _, test := range tests {
+		if got := alternateLink(test.links); got != test.want {
+			t.Errorf("%v: alternateLink() = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
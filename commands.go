@@ -3,8 +3,17 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,28 +25,144 @@ import (
 type state struct {
 	db     *database.Queries
 	config *config.Config
+	output string
 }
 
 type command struct {
 	name      string
 	arguments []string
+	flags     *flag.FlagSet
+}
+
+// commandInfo describes a command for the registry, help is generated from usage, description and flags
+type commandInfo struct {
+	usage       string
+	description string
+	flags       func(*flag.FlagSet)
+	// dashArguments keeps arguments like -java that name no flag as positional instead of failing
+	dashArguments bool
+	handler       func(*state, command) error
 }
 
 type commands struct {
-	cmds map[string]func(*state, command) error
+	cmds map[string]commandInfo
 }
 
 func (c *commands) run(s *state, cmd command) error {
-	commandFunc, ok := c.cmds[cmd.name]
+	info, ok := c.cmds[cmd.name]
 	if !ok {
-		return fmt.Errorf("command does not exist")
+		if suggestion := c.suggest(cmd.name); suggestion != "" {
+			return fmt.Errorf("command %v does not exist, did you mean %v?", cmd.name, suggestion)
+		}
+		return fmt.Errorf("command %v does not exist, use help to list the commands", cmd.name)
+	}
+
+	flags := info.flagSet(cmd.name)
+	arguments, err := parseFlags(flags, cmd.arguments, info.dashArguments)
+	if errors.Is(err, flag.ErrHelp) {
+		return c.printCommandHelp(cmd.name)
+	} else if err != nil {
+		return fmt.Errorf("%v, usage: %v", err, info.usage)
+	}
+
+	cmd.arguments = arguments
+	cmd.flags = flags
+
+	if flags.Lookup("output") != nil && cmd.stringFlag("output") != "" {
+		s.output = cmd.stringFlag("output")
+	}
+	if !validOutputFormat(s.output) {
+		return fmt.Errorf("unknown output format %v, use one of: %v", s.output, strings.Join(outputFormats, ", "))
+	}
+
+	return info.handler(s, cmd)
+}
+
+func (info commandInfo) flagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	if info.flags != nil {
+		info.flags(flags)
+	}
+
+	return flags
+}
+
+func (cmd command) boolFlag(name string) bool {
+	return cmd.flags.Lookup(name).Value.(flag.Getter).Get().(bool)
+}
+
+func (cmd command) intFlag(name string) int {
+	return cmd.flags.Lookup(name).Value.(flag.Getter).Get().(int)
+}
+
+func (cmd command) stringFlag(name string) string {
+	return cmd.flags.Lookup(name).Value.(flag.Getter).Get().(string)
+}
+
+// parseFlags lets flags appear before or after the positional arguments, an unknown flag is an error
+// unless dashArguments is set, then it is kept as a positional argument, e.g. the -java of search rust -java
+func parseFlags(flags *flag.FlagSet, arguments []string, dashArguments bool) ([]string, error) {
+	var positional []string
+
+	for i := 0; i < len(arguments); i++ {
+		argument := arguments[i]
+		if argument == "--" {
+			return append(positional, arguments[i+1:]...), nil
+		}
+
+		name, hasValue, ok := flagName(argument)
+		if !ok {
+			positional = append(positional, argument)
+			continue
+		}
+		if (name == "h" || name == "help") && flags.Lookup(name) == nil {
+			return nil, flag.ErrHelp
+		}
+
+		registered := flags.Lookup(name)
+		if registered == nil {
+			if dashArguments {
+				positional = append(positional, argument)
+				continue
+			}
+			if suggestion := suggestFlag(flags, name); suggestion != "" {
+				return nil, fmt.Errorf("unknown flag %v, did you mean --%v?", argument, suggestion)
+			}
+			return nil, fmt.Errorf("unknown flag %v", argument)
+		}
+
+		// a flag that is not a switch takes the next argument as its value unless it was given as -name=value
+		end := i + 1
+		if boolFlag, isBool := registered.Value.(interface{ IsBoolFlag() bool }); !hasValue && !(isBool && boolFlag.IsBoolFlag()) && end < len(arguments) {
+			end++
+		}
+		if err := flags.Parse(arguments[i:end]); err != nil {
+			return nil, err
+		}
+		i = end - 1
 	}
 
-	return commandFunc(s, cmd)
+	return positional, nil
 }
 
-func (c *commands) register(name string, f func(*state, command) error) {
-	c.cmds[name] = f
+// flagName returns the name of a -name, --name or -name=value argument
+func flagName(argument string) (string, bool, bool) {
+	if len(argument) < 2 || argument[0] != '-' {
+		return "", false, false
+	}
+
+	name := strings.TrimPrefix(argument[1:], "-")
+	name, _, hasValue := strings.Cut(name, "=")
+	if name == "" || name[0] == '-' {
+		return "", false, false
+	}
+
+	return name, hasValue, true
+}
+
+func (c *commands) register(name string, info commandInfo) {
+	c.cmds[name] = info
 }
 
 func handlerLogin(s *state, cmd command) error {
@@ -78,8 +203,8 @@ func handlerRegister(s *state, cmd command) error {
 
 	user, err := s.db.CreateUser(context.Background(), database.CreateUserParams{
 		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
 		Name:      cmd.arguments[0],
 	})
 
@@ -110,6 +235,14 @@ func handlerUsers(s *state, cmd command) error {
 		return fmt.Errorf("cannot get users, error: %v", err)
 	}
 
+	if s.output != "" {
+		records := make([]userRecord, 0, len(users))
+		for _, user := range users {
+			records = append(records, newUserRecord(user, s.config.CurrUserName))
+		}
+		return writeRecords(s.output, records)
+	}
+
 	for _, user := range users {
 		if user.Name == s.config.CurrUserName {
 			fmt.Println("*", user.Name, "(current)")
@@ -122,42 +255,184 @@ func handlerUsers(s *state, cmd command) error {
 }
 
 func handlerAgg(s *state, cmd command) error {
-	if len(cmd.arguments) == 0 {
+	concurrency := cmd.intFlag("concurrency")
+	once := cmd.boolFlag("once")
+	maxFailures := cmd.intFlag("max-failures")
+
+	if concurrency < 1 {
+		return fmt.Errorf("concurrency has to be at least 1")
+	}
+
+	if maxFailures < 1 {
+		return fmt.Errorf("max-failures has to be at least 1")
+	}
+
+	if len(cmd.arguments) == 0 && !once {
 		return fmt.Errorf("need a time argument, examples: 1s, 1m, 1h, 1h10m10s")
 	}
 
-	timeBetweenRequests, err := time.ParseDuration(cmd.arguments[0])
+	var timeBetweenRequests time.Duration
+	if len(cmd.arguments) != 0 {
+		var err error
+		timeBetweenRequests, err = time.ParseDuration(cmd.arguments[0])
+		if err != nil {
+			return fmt.Errorf("improper time format, examples of proper format: 1s, 1m, 1h, 1h10m10s")
+		}
+	}
+
+	// cancelling stops in-flight fetches, db writes for an already fetched feed still finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	if err != nil {
-		return fmt.Errorf("improper time format, examples of proper format: 1s, 1m, 1h, 1h10m10s")
+	if once {
+		return scrapeAllFeeds(ctx, s, concurrency, maxFailures, time.Now().UTC().Add(-timeBetweenRequests))
 	}
 
 	ticker := time.NewTicker(timeBetweenRequests)
-	fmt.Println("Collecting feeds every", timeBetweenRequests)
+	defer ticker.Stop()
+	fmt.Println("Collecting feeds every", timeBetweenRequests, "with", concurrency, "workers")
+
+	ticks := make(chan time.Time)
+	var workers sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for tickStart := range ticks {
+				err := scrapeFeeds(ctx, s, tickStart, maxFailures)
+				if err != nil && !errors.Is(err, errNoStaleFeed) {
+					fmt.Println(err)
+				}
+			}
+		}()
+	}
 
-	for ; ; <-ticker.C {
-		err := scrapeFeeds(s)
-		if err != nil {
-			fmt.Println(err)
+	// every tick each worker claims a different feed that was not fetched since the tick started
+	for {
+		tickStart := time.Now().UTC()
+		for i := 0; i < concurrency; i++ {
+			select {
+			case ticks <- tickStart:
+			case <-ctx.Done():
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			close(ticks)
+			workers.Wait()
+			fmt.Println("stopped collecting feeds")
+			return nil
+		}
+	}
+
+}
+
+// scrapeAllFeeds fetches every feed last fetched before staleBefore exactly once
+func scrapeAllFeeds(ctx context.Context, s *state, concurrency, maxFailures int, staleBefore time.Time) error {
+	var failed atomic.Int32
+	var workers sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for ctx.Err() == nil {
+				feed, err := claimNextFeed(ctx, s, staleBefore)
+				if errors.Is(err, errNoStaleFeed) {
+					return
+				} else if err != nil {
+					fmt.Println(err)
+					failed.Add(1)
+					return
+				}
+
+				if err := scrapeFeed(ctx, s, feed, maxFailures); err != nil {
+					fmt.Println(err)
+					failed.Add(1)
+				}
+			}
+		}()
+	}
+
+	workers.Wait()
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("interrupted before every feed was fetched")
+	}
+	if failed.Load() > 0 {
+		return fmt.Errorf("%v feeds could not be fetched", failed.Load())
+	}
+
+	fmt.Println("every stale feed has been fetched")
+	return nil
+}
+
+// resolveFeed returns feedURL when it is a feed, otherwise the first feed discovered from the page
+func resolveFeed(ctx context.Context, feedURL string) (discoveredFeed, error) {
+	feeds, err := discoverFeeds(ctx, feedURL)
+	if err != nil {
+		return discoveredFeed{}, err
+	}
+
+	if feeds[0].url == feedURL {
+		return feeds[0], nil
+	}
+
+	fmt.Printf("%v is a web page, using its feed %v\n", feedURL, feeds[0].url)
+	if len(feeds) > 1 {
+		fmt.Println("the page also links to these feeds, add them with addfeed if you want them too:")
+		for _, feed := range feeds[1:] {
+			if feed.title != "" {
+				fmt.Printf("* %v (%v)\n", feed.url, feed.title)
+			} else {
+				fmt.Printf("* %v\n", feed.url)
+			}
 		}
 	}
 
+	return feeds[0], nil
 }
 
+// handlerAddFeed takes addfeed [name] <url>, without a name the feed is named after its channel title
 func handlerAddFeed(s *state, cmd command, user database.User) error {
+	var name, feedURL string
 	switch len(cmd.arguments) {
 	case 0:
-		return fmt.Errorf("need a name and url for feed, the first argument is the name, the second is the url, use quotation marks to wrap the name and url")
+		return fmt.Errorf("need a url for feed, optionally preceded by a name, use quotation marks to wrap the name")
 	case 1:
-		return fmt.Errorf("need url for feed")
+		feedURL = cmd.arguments[0]
+	default:
+		name, feedURL = cmd.arguments[0], cmd.arguments[1]
+	}
+
+	if cmd.boolFlag("no-discover") {
+		if name == "" {
+			return fmt.Errorf("need a name for the feed with --no-discover, the channel is not fetched")
+		}
+	} else {
+		discovered, err := resolveFeed(context.Background(), feedURL)
+		if err != nil {
+			return fmt.Errorf("cannot find a feed at %v, use --no-discover to add it anyway, error: %v", feedURL, err)
+		}
+		feedURL = discovered.url
+
+		if name == "" {
+			name = strings.TrimSpace(discovered.title)
+		}
+		if name == "" {
+			name = feedURL
+		}
 	}
 
 	feed, err := s.db.CreateFeed(context.Background(), database.CreateFeedParams{
 		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		Name:      cmd.arguments[0],
-		Url:       cmd.arguments[1],
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		Name:      name,
+		Url:       feedURL,
 		UserID:    user.ID,
 	})
 
@@ -172,8 +447,8 @@ func handlerAddFeed(s *state, cmd command, user database.User) error {
 
 	if _, err := s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
 		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
 		UserID:    user.ID,
 		FeedID:    feed.ID,
 	}); err != nil {
@@ -185,6 +460,100 @@ func handlerAddFeed(s *state, cmd command, user database.User) error {
 	return nil
 }
 
+func handlerImport(s *state, cmd command, user database.User) error {
+	if len(cmd.arguments) < 2 || cmd.arguments[0] != "opml" {
+		return fmt.Errorf("usage: import opml <file>")
+	}
+
+	outlines, err := readOPML(cmd.arguments[1])
+	if err != nil {
+		return fmt.Errorf("cannot read opml file, error: %v", err)
+	}
+
+	var created, existing int
+	var invalid []string
+
+	for _, outline := range outlines {
+		feedURL := strings.TrimSpace(outline.XMLURL)
+		if !validFeedURL(feedURL) {
+			invalid = append(invalid, fmt.Sprintf("%v (xmlUrl: %q)", outline.name(), feedURL))
+			continue
+		}
+
+		feedId, err := s.db.GetFeedId(context.Background(), feedURL)
+		if err == nil {
+			existing++
+		} else if err == sql.ErrNoRows {
+			feed, err := s.db.CreateFeed(context.Background(), database.CreateFeedParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now().UTC(),
+				UpdatedAt: time.Now().UTC(),
+				Name:      outline.name(),
+				Url:       feedURL,
+				UserID:    user.ID,
+			})
+			if err != nil {
+				invalid = append(invalid, fmt.Sprintf("%v (cannot add feed, error: %v)", feedURL, err))
+				continue
+			}
+			feedId = feed.ID
+			created++
+		} else {
+			return fmt.Errorf("cannot look up feed %v, error: %v", feedURL, err)
+		}
+
+		if _, err := s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			UserID:    user.ID,
+			FeedID:    feedId,
+		}); err != nil {
+			if pgErr, ok := err.(*pq.Error); !ok || pgErr.Code != "23505" {
+				fmt.Println("cannot follow feed", feedURL, "error:", err)
+			}
+		}
+	}
+
+	fmt.Printf("created: %v, already existing: %v, invalid: %v\n", created, existing, len(invalid))
+	for _, entry := range invalid {
+		fmt.Println("invalid:", entry)
+	}
+
+	return nil
+}
+
+func handlerExport(s *state, cmd command, user database.User) error {
+	if len(cmd.arguments) == 0 || cmd.arguments[0] != "opml" {
+		return fmt.Errorf("usage: export opml [file], writes to stdout without a file")
+	}
+
+	feeds, err := s.db.GetFollowedFeedsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("cannot get followed feeds, error: %v", err)
+	}
+
+	output := os.Stdout
+	if len(cmd.arguments) > 1 {
+		file, err := os.Create(cmd.arguments[1])
+		if err != nil {
+			return fmt.Errorf("cannot create export file, error: %v", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	if err := writeOPML(output, "Gator subscriptions of "+user.Name, feeds); err != nil {
+		return fmt.Errorf("cannot write opml, error: %v", err)
+	}
+
+	if output != os.Stdout {
+		fmt.Println("exported", len(feeds), "feeds to", cmd.arguments[1])
+	}
+
+	return nil
+}
+
 func handlerFeeds(s *state, cmd command) error {
 
 	feeds, err := s.db.GetFeeds(context.Background())
@@ -193,6 +562,14 @@ func handlerFeeds(s *state, cmd command) error {
 		return fmt.Errorf("cannot get feeds, error: %v", err)
 	}
 
+	if s.output != "" {
+		records := make([]feedRecord, 0, len(feeds))
+		for _, feed := range feeds {
+			records = append(records, newFeedRecord(feed))
+		}
+		return writeRecords(s.output, records)
+	}
+
 	if len(feeds) == 0 {
 		fmt.Println("There are no active feeds")
 		return nil
@@ -205,6 +582,88 @@ func handlerFeeds(s *state, cmd command) error {
 	return nil
 }
 
+func handlerFeedStatus(s *state, cmd command) error {
+	failing := cmd.boolFlag("failing")
+	neverFetched := cmd.boolFlag("never-fetched")
+
+	statuses, err := s.db.GetFeedStatuses(context.Background())
+	if err != nil {
+		return fmt.Errorf("cannot get feed statuses, error: %v", err)
+	}
+
+	shown := 0
+	for _, feed := range statuses {
+		if failing && feed.FetchFailures == 0 && !feed.Disabled {
+			continue
+		}
+		if neverFetched && feed.LastFetchedAt.Valid {
+			continue
+		}
+
+		shown++
+		fmt.Printf("%v: %v (%v)\n", shown, feed.Name, feed.Url)
+		fmt.Printf("   last fetched: %v, last success: %v, last status: %v\n",
+			formatNullTime(feed.LastFetchedAt), formatNullTime(feed.LastSuccessAt), formatStatus(feed.LastHttpStatus))
+
+		if feed.LastFetchError.Valid {
+			fmt.Printf("   error: %v (%v failures in a row)\n", feed.LastFetchError.String, feed.FetchFailures)
+		}
+		if feed.Disabled {
+			fmt.Println("   disabled")
+		}
+
+		if feed.ItemCount > 1 {
+			average := time.Duration(feed.AvgSecondsBetweenPosts * float64(time.Second)).Round(time.Minute)
+			fmt.Printf("   items: %v, a post about every %v\n", feed.ItemCount, average)
+		} else {
+			fmt.Printf("   items: %v\n", feed.ItemCount)
+		}
+	}
+
+	if shown == 0 {
+		fmt.Println("There are no feeds matching the filters")
+	}
+
+	return nil
+}
+
+// handlerEnable clears the failures of a feed so agg fetches it again, also when it was disabled
+func handlerEnable(s *state, cmd command) error {
+	if len(cmd.arguments) == 0 {
+		return fmt.Errorf("need a url argument")
+	}
+
+	enabled, err := s.db.EnableFeed(context.Background(), cmd.arguments[0])
+	if err != nil {
+		return fmt.Errorf("cannot enable feed, error: %v", err)
+	}
+	if enabled == 0 {
+		return fmt.Errorf("feed %v does not exist", cmd.arguments[0])
+	}
+
+	fmt.Println("feed enabled:", cmd.arguments[0])
+	return nil
+}
+
+func nullString(value string) sql.NullString {
+	value = strings.TrimSpace(value)
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return "never"
+	}
+	return t.Time.Local().Format(time.DateTime)
+}
+
+func formatStatus(status sql.NullInt32) string {
+	if !status.Valid {
+		return "none"
+	}
+	return strconv.Itoa(int(status.Int32))
+}
+
 func handlerFollow(s *state, cmd command, user database.User) error {
 	if len(cmd.arguments) == 0 {
 		return fmt.Errorf("needs a URL link")
@@ -220,8 +679,8 @@ func handlerFollow(s *state, cmd command, user database.User) error {
 
 	followedData, err := s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
 		ID:        uuid.New(),
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
 		UserID:    user.ID,
 		FeedID:    feedId,
 	})
@@ -248,6 +707,14 @@ func handlerFollowingList(s *state, cmd command, user database.User) error {
 		return fmt.Errorf("cannot get followers list, error: %v", err)
 	}
 
+	if s.output != "" {
+		records := make([]followRecord, 0, len(followList))
+		for _, follow := range followList {
+			records = append(records, newFollowRecord(follow))
+		}
+		return writeRecords(s.output, records)
+	}
+
 	for _, feed := range followList {
 		fmt.Printf("%v\n", feed.FeedName)
 	}
@@ -287,35 +754,166 @@ func handlerUnfollow(s *state, cmd command, user database.User) error {
 	return nil
 }
 
-func scrapeFeeds(s *state) error {
-	nextFeed, err := s.db.GetNextFeedToFetch(context.Background())
+var errNoStaleFeed = errors.New("no feed needs to be fetched")
 
+// claimNextFeed claims the least recently fetched feed not fetched since staleBefore, so parallel workers never share a feed
+func claimNextFeed(ctx context.Context, s *state, staleBefore time.Time) (database.Feed, error) {
+	nextFeed, err := s.db.ClaimNextFeedToFetch(ctx, database.ClaimNextFeedToFetchParams{
+		FetchedAt: sql.NullTime{
+			Time:  time.Now().UTC(),
+			Valid: true,
+		},
+		StaleBefore: sql.NullTime{
+			Time:  staleBefore,
+			Valid: true,
+		},
+	})
+
+	if err == sql.ErrNoRows {
+		return database.Feed{}, errNoStaleFeed
+	} else if err != nil {
+		return database.Feed{}, fmt.Errorf("cannot get next feed, error: %v", err)
+	}
+
+	return nextFeed, nil
+}
+
+func scrapeFeeds(ctx context.Context, s *state, staleBefore time.Time, maxFailures int) error {
+	nextFeed, err := claimNextFeed(ctx, s, staleBefore)
 	if err != nil {
-		return fmt.Errorf("cannot get next feed, error: %v", err)
+		return err
 	}
 
-	s.db.MarkFeedFetched(context.Background(), database.MarkFeedFetchedParams{
-		LastFetchedAt: sql.NullTime{
-			Time:  time.Now(),
+	return scrapeFeed(ctx, s, nextFeed, maxFailures)
+}
+
+// fetchBackoff doubles the wait after every consecutive failure, up to a day
+func fetchBackoff(failures int32) time.Duration {
+	backoff := time.Minute
+	for i := int32(1); i < failures && backoff < 24*time.Hour; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, 24*time.Hour)
+}
+
+// recordFetchFailure puts the feed in backoff and disables it after maxFailures consecutive failures
+func recordFetchFailure(ctx context.Context, s *state, feed database.Feed, response feedResponse, fetchErr error, maxFailures int) {
+	failures := feed.FetchFailures + 1
+	disabled := int(failures) >= maxFailures
+
+	if err := s.db.RecordFeedFailure(ctx, database.RecordFeedFailureParams{
+		ID: feed.ID,
+		LastFetchError: sql.NullString{
+			String: fetchErr.Error(),
+			Valid:  true,
+		},
+		NextFetchAt: sql.NullTime{
+			Time:  time.Now().UTC().Add(fetchBackoff(failures)),
 			Valid: true,
 		},
-		ID: nextFeed.ID,
+		Disabled:       disabled,
+		LastHttpStatus: httpStatus(response),
+	}); err != nil {
+		fmt.Println("cannot record fetch failure for feed id", feed.ID, "error:", err)
+		return
+	}
+
+	if disabled {
+		fmt.Println("feed", feed.Url, "has been disabled after", failures, "failures in a row")
+	}
+}
+
+func httpStatus(response feedResponse) sql.NullInt32 {
+	return sql.NullInt32{
+		Int32: int32(response.statusCode),
+		Valid: response.statusCode != 0,
+	}
+}
+
+func scrapeFeed(ctx context.Context, s *state, nextFeed database.Feed, maxFailures int) error {
+	data, response, err := fetchFeed(ctx, nextFeed.Url, feedCache{
+		etag:         nextFeed.Etag.String,
+		lastModified: nextFeed.LastModified.String,
 	})
+	// db writes are not cancelled, so a feed being stored when agg shuts down is stored completely
+	writeCtx := context.WithoutCancel(ctx)
 
-	data, err := fetchFeed(context.Background(), nextFeed.Url)
-	if err != nil {
-		return fmt.Errorf("cannot get contents of feed, error: %v", err)
+	if err != nil && !errors.Is(err, errNotModified) {
+		// a fetch cut short by shutdown is not the feed's fault
+		if ctx.Err() == nil {
+			recordFetchFailure(writeCtx, s, nextFeed, response, err, maxFailures)
+		}
+		return fmt.Errorf("cannot get contents of feed %v, error: %v", nextFeed.Url, err)
+	}
+
+	if err := s.db.RecordFeedSuccess(writeCtx, database.RecordFeedSuccessParams{
+		ID: nextFeed.ID,
+		LastSuccessAt: sql.NullTime{
+			Time:  time.Now().UTC(),
+			Valid: true,
+		},
+		LastHttpStatus: httpStatus(response),
+	}); err != nil {
+		fmt.Println("cannot record successful fetch for feed id", nextFeed.ID, "error:", err)
+	}
+
+	if errors.Is(err, errNotModified) {
+		fmt.Println("feed has not changed since the last fetch:", nextFeed.Url)
+		return nil
+	}
+
+	if err := s.db.UpdateFeedCache(writeCtx, database.UpdateFeedCacheParams{
+		Etag: sql.NullString{
+			String: response.cache.etag,
+			Valid:  response.cache.etag != "",
+		},
+		LastModified: sql.NullString{
+			String: response.cache.lastModified,
+			Valid:  response.cache.lastModified != "",
+		},
+		ID: nextFeed.ID,
+	}); err != nil {
+		fmt.Println("cannot store cache headers for feed id", nextFeed.ID, "error:", err)
+	}
+
+	if err := s.db.UpdateFeedMetadata(writeCtx, database.UpdateFeedMetadataParams{
+		SiteUrl:     nullString(data.Channel.Link),
+		Title:       nullString(data.Channel.Title),
+		Description: nullString(data.Channel.Description),
+		Language:    nullString(data.Channel.Language),
+		ImageUrl:    nullString(data.Channel.Image),
+		Generator:   nullString(data.Channel.Generator),
+		ID:          nextFeed.ID,
+	}); err != nil {
+		fmt.Println("cannot store channel metadata for feed id", nextFeed.ID, "error:", err)
 	}
 
 	for _, item := range data.Channel.Item {
-		parsedTime, errTime := time.Parse("Mon, 02 Jan 2006 15:04:05 MST", item.PubDate)
-		if errTime != nil {
-			parsedTime = time.Time{}
+		guid := itemGUID(item)
+		if guid == "" {
+			fmt.Println("skipping post without guid, link or title for feed id", nextFeed.ID)
+			continue
+		}
+
+		parsedTime, errTime := itemPublishedAt(item)
+
+		// posts stored before guids were tracked use their link as guid, give them the real one so they are not stored twice
+		if guid != item.Link {
+			if _, err := s.db.AdoptPostGuid(writeCtx, database.AdoptPostGuidParams{
+				Guid:   guid,
+				FeedID: nextFeed.ID,
+				Url:    item.Link,
+			}); err != nil {
+				fmt.Println("cannot update guid of post:", item.Link, "error:", err)
+				continue
+			}
 		}
-		post, err := s.db.CreatePost(context.Background(), database.CreatePostParams{
+
+		post, err := s.db.UpsertPost(writeCtx, database.UpsertPostParams{
 			ID:        uuid.New(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
 			Title: sql.NullString{
 				String: item.Title,
 				Valid:  item.Title != "",
@@ -330,19 +928,21 @@ func scrapeFeeds(s *state) error {
 				Valid: errTime == nil,
 			},
 			FeedID: nextFeed.ID,
+			Guid:   guid,
 		})
 
-		if err != nil {
-			if pgErr, ok := err.(*pq.Error); ok {
-				if pgErr.Code != "23505" {
-					fmt.Println("cannot get one post for feed id", nextFeed.ID, "error:", err)
-				}
-			} else {
-				fmt.Println("non-postgres error for one of the post:", ok)
+		// no row means the post is already stored and has not changed
+		if err == sql.ErrNoRows {
+			continue
+		} else if err != nil {
+			fmt.Println("cannot store one post for feed id", nextFeed.ID, "error:", err)
+		} else if post.Inserted {
+			fmt.Println("posts have been stored:", post.Title.String, post.Url, "for feed id", nextFeed.ID)
+			if errTime != nil {
+				fmt.Println("   stored without a publish date, it is sorted by when it was collected, error:", errTime)
 			}
-
 		} else {
-			fmt.Println("posts have been stored:", post, "for feed id", nextFeed.ID)
+			fmt.Println("post has been updated:", post.Title.String, post.Url, "for feed id", nextFeed.ID)
 		}
 	}
 
@@ -351,34 +951,103 @@ func scrapeFeeds(s *state) error {
 }
 
 func handlerBrowse(s *state, cmd command, user database.User) error {
+	all := cmd.boolFlag("all")
+	feed := cmd.stringFlag("feed")
+	since := cmd.stringFlag("since")
+	until := cmd.stringFlag("until")
+	offset := cmd.intFlag("offset")
+	page := cmd.intFlag("page")
+	order := cmd.stringFlag("order")
 
 	var limit int32 = 2
 
 	if len(cmd.arguments) != 0 {
 		newLimit, err := strconv.Atoi(cmd.arguments[0])
-		if err != nil {
+		if err != nil || newLimit < 1 {
 			return fmt.Errorf("invalid arguments, it has to be a number or blank(defualt 2)")
 		}
 		limit = int32(newLimit)
 	}
 
-	posts, err := s.db.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  limit,
+	if order != "newest" && order != "oldest" {
+		return fmt.Errorf("order has to be newest or oldest")
+	}
+
+	if offset < 0 || page < 0 {
+		return fmt.Errorf("offset and page cannot be negative")
+	}
+	if page > 0 {
+		offset = (page - 1) * int(limit)
+	}
+
+	sinceTime, err := parseBrowseTime(since)
+	if err != nil {
+		return fmt.Errorf("invalid since value, error: %v", err)
+	}
+	untilTime, err := parseBrowseTime(until)
+	if err != nil {
+		return fmt.Errorf("invalid until value, error: %v", err)
+	}
+
+	posts, err := s.db.GetPostsForUserFiltered(context.Background(), database.GetPostsForUserFilteredParams{
+		UserID:      user.ID,
+		IncludeRead: all,
+		Feed: sql.NullString{
+			String: feed,
+			Valid:  feed != "",
+		},
+		Since:       sinceTime,
+		Until:       untilTime,
+		OldestFirst: order == "oldest",
+		Limit:       limit,
+		Offset:      int32(offset),
 	})
 
 	if err != nil {
 		return fmt.Errorf("cannot load posts, please try again")
 	}
 
-	if len(posts) == 0 {
-		fmt.Println("you have no current posts from any feeds, please try again or follow a feed to get browse posts")
+	if len(posts) == 0 && s.output == "" {
+		fmt.Println("you have no unread posts matching the filters, use --all to include read posts or follow a feed to get browse posts")
 		return nil
 	}
 
+	return printPosts(s, posts)
+
+}
+
+// parseBrowseTime accepts the same dates feeds use, or a duration meaning that long ago
+func parseBrowseTime(value string) (sql.NullTime, error) {
+	if value == "" {
+		return sql.NullTime{}, nil
+	}
+
+	if duration, err := time.ParseDuration(value); err == nil {
+		return sql.NullTime{Time: time.Now().UTC().Add(-duration), Valid: true}, nil
+	}
+
+	parsed, err := parsePubDate(value)
+	if err != nil {
+		return sql.NullTime{}, err
+	}
+
+	return sql.NullTime{Time: parsed, Valid: true}, nil
+}
+
+// printPosts writes records when an output format is set, otherwise the browse text
+func printPosts(s *state, posts []database.Post) error {
+	if s.output != "" {
+		records := make([]postRecord, 0, len(posts))
+		for _, post := range posts {
+			records = append(records, newPostRecord(post))
+		}
+		return writeRecords(s.output, records)
+	}
+
 	for _, post := range posts {
 		fmt.Println()
 		fmt.Println()
+		fmt.Println("id:", post.ID)
 		fmt.Println("title:", post.Title.String)
 
 		fmt.Println()
@@ -393,5 +1062,177 @@ func handlerBrowse(s *state, cmd command, user database.User) error {
 	}
 
 	return nil
+}
+
+func handlerRead(s *state, cmd command, user database.User) error {
+	if cmd.boolFlag("all") {
+		marked, err := s.db.MarkAllPostsRead(context.Background(), database.MarkAllPostsReadParams{
+			UserID: user.ID,
+			ReadAt: sql.NullTime{
+				Time:  time.Now().UTC(),
+				Valid: true,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("cannot mark posts as read, error: %v", err)
+		}
+
+		fmt.Println(marked, "posts marked as read")
+		return nil
+	}
+
+	if len(cmd.arguments) == 0 {
+		return fmt.Errorf("need a post id or --all")
+	}
+
+	postId, err := uuid.Parse(cmd.arguments[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id")
+	}
+
+	if err := s.db.MarkPostRead(context.Background(), database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: postId,
+		ReadAt: sql.NullTime{
+			Time:  time.Now().UTC(),
+			Valid: true,
+		},
+	}); err != nil {
+		if pgErr, ok := err.(*pq.Error); ok {
+			if pgErr.Code == "23503" {
+				return fmt.Errorf("post does not exist")
+			}
+		}
+		return fmt.Errorf("cannot mark post as read, error: %v", err)
+	}
+
+	fmt.Println("post marked as read")
+	return nil
+}
+
+func handlerUnread(s *state, cmd command, user database.User) error {
+	if len(cmd.arguments) == 0 {
+		return fmt.Errorf("need a post id")
+	}
+
+	postId, err := uuid.Parse(cmd.arguments[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id")
+	}
+
+	marked, err := s.db.MarkPostUnread(context.Background(), database.MarkPostUnreadParams{
+		UserID: user.ID,
+		PostID: postId,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot mark post as unread, error: %v", err)
+	}
+
+	if marked == 0 {
+		return fmt.Errorf("post is not marked as read")
+	}
+
+	fmt.Println("post marked as unread")
+	return nil
+}
+
+func handlerSave(s *state, cmd command, user database.User) error {
+	if len(cmd.arguments) == 0 {
+		return fmt.Errorf("need a post id")
+	}
+
+	postId, err := uuid.Parse(cmd.arguments[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id")
+	}
+
+	if err := s.db.SavePost(context.Background(), database.SavePostParams{
+		UserID:    user.ID,
+		PostID:    postId,
+		CreatedAt: time.Now().UTC(),
+	}); err != nil {
+		if pgErr, ok := err.(*pq.Error); ok {
+			if pgErr.Code == "23503" {
+				return fmt.Errorf("post does not exist")
+			}
+		}
+		return fmt.Errorf("cannot save post, error: %v", err)
+	}
+
+	fmt.Println("post saved")
+	return nil
+}
+
+func handlerUnsave(s *state, cmd command, user database.User) error {
+	if len(cmd.arguments) == 0 {
+		return fmt.Errorf("need a post id")
+	}
+
+	postId, err := uuid.Parse(cmd.arguments[0])
+	if err != nil {
+		return fmt.Errorf("invalid post id")
+	}
+
+	removed, err := s.db.UnsavePost(context.Background(), database.UnsavePostParams{
+		UserID: user.ID,
+		PostID: postId,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot unsave post, error: %v", err)
+	}
+
+	if removed == 0 {
+		return fmt.Errorf("post is not saved")
+	}
+
+	fmt.Println("post removed from saved posts")
+	return nil
+}
+
+func handlerSaved(s *state, cmd command, user database.User) error {
+	posts, err := s.db.GetSavedPostsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("cannot load saved posts, error: %v", err)
+	}
+
+	if len(posts) == 0 && s.output == "" {
+		fmt.Println("you have no saved posts, use the save command to save one")
+		return nil
+	}
+
+	return printPosts(s, posts)
+}
+
+func handlerSearch(s *state, cmd command, user database.User) error {
+	query := strings.TrimSpace(strings.Join(cmd.arguments, " "))
+	if query == "" {
+		return fmt.Errorf(`need a search query, wrap phrases in double quotes, example: search '"exact phrase" -excluded'`)
+	}
+
+	limit := cmd.intFlag("limit")
+	if limit < 1 {
+		return fmt.Errorf("limit has to be a positive number")
+	}
+
+	results, err := s.db.SearchPosts(context.Background(), database.SearchPostsParams{
+		Query:    query,
+		AllFeeds: cmd.boolFlag("all"),
+		UserID:   user.ID,
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot search posts, error: %v", err)
+	}
+
+	if len(results) == 0 && s.output == "" {
+		fmt.Println("no posts match", query)
+		return nil
+	}
+
+	posts := make([]database.Post, 0, len(results))
+	for _, result := range results {
+		posts = append(posts, result.Post)
+	}
 
+	return printPosts(s, posts)
 }
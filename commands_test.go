@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testFlagSet() *flag.FlagSet {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	flags.Bool("all", false, "")
+	flags.Int("limit", 10, "")
+	flags.String("feed", "", "")
+	return flags
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name          string
+		arguments     []string
+		dashArguments bool
+		want          []string
+		all           bool
+		limit         int
+		feed          string
+	}{
+		{"no flags", []string{"a", "b"}, false, []string{"a", "b"}, false, 10, ""},
+		{"bool flag", []string{"--all", "a"}, false, []string{"a"}, true, 10, ""},
+		{"bool flag with value", []string{"a", "-all=false"}, false, []string{"a"}, false, 10, ""},
+		{"value flag", []string{"--limit", "5", "a"}, false, []string{"a"}, false, 5, ""},
+		{"value flag after positional", []string{"a", "-limit", "5"}, false, []string{"a"}, false, 5, ""},
+		{"name=value", []string{"--feed=https://example.com/rss", "a"}, false, []string{"a"}, false, 10, "https://example.com/rss"},
+		{"interleaved", []string{"a", "--all", "b", "--feed", "x", "c"}, false, []string{"a", "b", "c"}, true, 10, "x"},
+		{"double dash ends flags", []string{"a", "--", "--all", "-b"}, false, []string{"a", "--all", "-b"}, false, 10, ""},
+		{"single dash is positional", []string{"-"}, false, []string{"-"}, false, 10, ""},
+		{"dash arguments kept", []string{"rust", "-java", "--all"}, true, []string{"rust", "-java"}, true, 10, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			flags := testFlagSet()
+			got, err := parseFlags(flags, test.arguments, test.dashArguments)
+			if err != nil {
+				t.Fatalf("parseFlags(%q) error: %v", test.arguments, err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("parseFlags(%q) = %q, want %q", test.arguments, got, test.want)
+			}
+
+			cmd := command{flags: flags}
+			if all, limit, feed := cmd.boolFlag("all"), cmd.intFlag("limit"), cmd.stringFlag("feed"); all != test.all || limit != test.limit || feed != test.feed {
+				t.Errorf("parseFlags(%q) set all=%v limit=%v feed=%q, want all=%v limit=%v feed=%q",
+					test.arguments, all, limit, feed, test.all, test.limit, test.feed)
+			}
+		})
+	}
+}
+
+func TestParseFlagsErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments []string
+		want      string
+	}{
+		{"unknown flag with suggestion", []string{"--al", "a"}, "unknown flag --al, did you mean --all?"},
+		{"mistyped value flag", []string{"-limt", "5"}, "unknown flag -limt, did you mean --limit?"},
+		{"unknown flag", []string{"--output", "json"}, "unknown flag --output"},
+		{"negative number", []string{"-5"}, "unknown flag -5"},
+		{"missing value", []string{"a", "--limit"}, "flag needs an argument"},
+		{"invalid value", []string{"--limit", "many"}, "invalid value"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseFlags(testFlagSet(), test.arguments, false)
+			if err == nil || !strings.Contains(err.Error(), test.want) {
+				t.Errorf("parseFlags(%q) error = %v, want %q", test.arguments, err, test.want)
+			}
+		})
+	}
+
+	for _, help := range []string{"-h", "--help", "-help"} {
+		if _, err := parseFlags(testFlagSet(), []string{"a", help}, true); !errors.Is(err, flag.ErrHelp) {
+			t.Errorf("parseFlags(%q) error = %v, want flag.ErrHelp", help, err)
+		}
+	}
+}
+
+func TestFlagName(t *testing.T) {
+	tests := []struct {
+		argument string
+		name     string
+		hasValue bool
+		ok       bool
+	}{
+		{"-all", "all", false, true},
+		{"--all", "all", false, true},
+		{"--limit=5", "limit", true, true},
+		{"-feed=", "feed", true, true},
+		{"-h", "h", false, true},
+		{"all", "", false, false},
+		{"-", "", false, false},
+		{"--", "", false, false},
+		{"---all", "", false, false},
+		{"--=5", "", false, false},
+	}
+
+	for _, test := range tests {
+		name, hasValue, ok := flagName(test.argument)
+		if name != test.name || hasValue != test.hasValue || ok != test.ok {
+			t.Errorf("flagName(%q) = %q, %v, %v, want %q, %v, %v",
+				test.argument, name, hasValue, ok, test.name, test.hasValue, test.ok)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// pubDateLayouts are tried in order, a layout day of "2" also accepts two digit days
+var pubDateLayouts = []string{
+	// RFC 1123 / RFC 822 as used by RSS 2.0
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"Mon, 2 Jan 2006 15:04:05 -0700 (MST)",
+	"Mon, 2 Jan 2006 15:04 -0700",
+	"Mon, 2 Jan 2006 15:04 MST",
+	"Mon, 2 Jan 06 15:04:05 -0700",
+	"Mon, 2 Jan 06 15:04:05 MST",
+	"Mon, 2 Jan 06 15:04 -0700",
+	"Mon, 2 Jan 06 15:04 MST",
+
+	// same as above with the optional weekday missing
+	"2 Jan 2006 15:04:05 -0700",
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04 -0700",
+	"2 Jan 2006 15:04 MST",
+	"2 Jan 06 15:04:05 -0700",
+	"2 Jan 06 15:04:05 MST",
+	"2 Jan 06 15:04 -0700",
+	"2 Jan 06 15:04 MST",
+
+	// RFC 3339 / W3C-DTF as used by atom, dc:date and JSON Feed
+	time.RFC3339Nano,
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02 15:04:05Z07:00",
+
+	// ISO 8601 with a basic offset, no colon in the zone
+	"2006-01-02T15:04:05-0700",
+	"2006-01-02T15:04:05.999999999-0700",
+
+	// ISO 8601 without a zone, read as UTC
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// rfc822Zones are the named zones RFC 822 allows, time.Parse only knows the local ones
+var rfc822Zones = map[string]int{
+	"UT":  0,
+	"GMT": 0,
+	"UTC": 0,
+	"Z":   0,
+	"EST": -5 * 60 * 60,
+	"EDT": -4 * 60 * 60,
+	"CST": -6 * 60 * 60,
+	"CDT": -5 * 60 * 60,
+	"MST": -7 * 60 * 60,
+	"MDT": -6 * 60 * 60,
+	"PST": -8 * 60 * 60,
+	"PDT": -7 * 60 * 60,
+}
+
+// parsePubDate parses the date formats used by RSS, RDF, atom and JSON feeds and normalizes to UTC, like every timestamp gator stores
+func parsePubDate(value string) (time.Time, error) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	for _, layout := range pubDateLayouts {
+		parsed, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+
+		// time.Parse reads a zone name it does not know as UTC, only trust the ones RFC 822 defines
+		if name, offset := parsed.Zone(); offset == 0 && strings.HasSuffix(layout, "MST") {
+			zoneOffset, ok := rfc822Zones[strings.ToUpper(name)]
+			if !ok {
+				return time.Time{}, fmt.Errorf("unknown time zone %v in date: %v", name, value)
+			}
+			parsed = parsed.Add(-time.Duration(zoneOffset) * time.Second)
+		}
+
+		return parsed.UTC(), nil
+	}
+
+	return time.Time{}, fmt.Errorf("unknown date format: %v", value)
+}
+
+// itemPublishedAt tries pubDate first, then dc:date and atom:updated
+func itemPublishedAt(item RSSItem) (time.Time, error) {
+	var lastErr error = fmt.Errorf("no date")
+
+	for _, value := range []string{item.PubDate, item.DCDate, item.AtomUpdated} {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+
+		parsed, err := parsePubDate(value)
+		if err == nil {
+			return parsed, nil
+		}
+		lastErr = err
+	}
+
+	return time.Time{}, lastErr
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePubDate(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+	}{
+		{"rfc 1123 numeric zone", "Mon, 02 Jan 2006 15:04:05 -0700", time.Date(2006, 1, 2, 22, 4, 5, 0, time.UTC)},
+		{"rfc 1123 named zone", "Mon, 02 Jan 2006 15:04:05 EST", time.Date(2006, 1, 2, 20, 4, 5, 0, time.UTC)},
+		{"rfc 1123 gmt", "Mon, 02 Jan 2006 15:04:05 GMT", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"rfc 1123 zone comment", "Mon, 02 Jan 2006 15:04:05 +0200 (EET)", time.Date(2006, 1, 2, 13, 4, 5, 0, time.UTC)},
+		{"rfc 822 without seconds", "Mon, 2 Jan 2006 15:04 PDT", time.Date(2006, 1, 2, 22, 4, 0, 0, time.UTC)},
+		{"rfc 822 two digit year", "Mon, 02 Jan 06 15:04:05 +0000", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"without weekday", "2 Jan 2006 15:04:05 -0500", time.Date(2006, 1, 2, 20, 4, 5, 0, time.UTC)},
+		{"extra whitespace", "  Mon,  02 Jan 2006\n15:04:05 +0000 ", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"rfc 3339", "2006-01-02T15:04:05+01:00", time.Date(2006, 1, 2, 14, 4, 5, 0, time.UTC)},
+		{"rfc 3339 fraction", "2006-01-02T15:04:05.123Z", time.Date(2006, 1, 2, 15, 4, 5, 123000000, time.UTC)},
+		{"w3c-dtf minutes", "2006-01-02T15:04+02:00", time.Date(2006, 1, 2, 13, 4, 0, 0, time.UTC)},
+		{"space separated", "2006-01-02 15:04:05Z", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"basic offset", "2006-01-02T15:04:05+0000", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"basic offset fraction", "2006-01-02T15:04:05.5-0130", time.Date(2006, 1, 2, 16, 34, 5, 500000000, time.UTC)},
+		{"no zone", "2006-01-02T15:04:05", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{"date only", "2006-01-02", time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := parsePubDate(test.value)
+			if err != nil {
+				t.Fatalf("parsePubDate(%q) error: %v", test.value, err)
+			}
+			if !got.Equal(test.want) {
+				t.Errorf("parsePubDate(%q) = %v, want %v", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParsePubDateInvalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"yesterday",
+		"Mon, 02 Jan 2006 15:04:05 CEST",
+		"2 Jan 2006 15:04 XYZ",
+	} {
+		if got, err := parsePubDate(value); err == nil {
+			t.Errorf("parsePubDate(%q) = %v, want an error", value, got)
+		}
+	}
+}
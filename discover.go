@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// feedPaths are probed when a page does not link to its feed
+var feedPaths = []string{"/feed", "/rss.xml", "/atom.xml"}
+
+var feedLinkTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+	"application/rdf+xml":   true,
+}
+
+var (
+	linkTagPattern   = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	attributePattern = regexp.MustCompile(`(?s)([a-zA-Z:-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+type discoveredFeed struct {
+	url   string
+	title string
+}
+
+// fetchPage returns the body, content type and final url after redirects of pageURL
+func fetchPage(ctx context.Context, pageURL string) ([]byte, string, *url.URL, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	request.Header.Set("User-Agent", "gator")
+
+	res, err := httpClient.Do(request)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, "", nil, fmt.Errorf("unexpected status: %v", res.Status)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return body, res.Header.Get("Content-Type"), res.Request.URL, nil
+}
+
+// discoverFeeds returns pageURL itself when it is a feed, otherwise the feeds the page links to or that exist at a common path
+func discoverFeeds(ctx context.Context, pageURL string) ([]discoveredFeed, error) {
+	body, contentType, base, err := fetchPage(ctx, pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if feed, err := parseFeed(body, contentType); err == nil {
+		return []discoveredFeed{{url: pageURL, title: html.UnescapeString(feed.Channel.Title)}}, nil
+	}
+
+	if feeds := feedLinks(body, base); len(feeds) != 0 {
+		return feeds, nil
+	}
+
+	var feeds []discoveredFeed
+	for _, path := range feedPaths {
+		candidate := base.ResolveReference(&url.URL{Path: path}).String()
+
+		body, contentType, _, err := fetchPage(ctx, candidate)
+		if err != nil {
+			continue
+		}
+		if feed, err := parseFeed(body, contentType); err == nil {
+			feeds = append(feeds, discoveredFeed{url: candidate, title: html.UnescapeString(feed.Channel.Title)})
+		}
+	}
+
+	if len(feeds) == 0 {
+		return nil, fmt.Errorf("%v is not a feed and does not link to one", pageURL)
+	}
+
+	return feeds, nil
+}
+
+// feedLinks parses the <link rel="alternate"> tags of an html page that point to feeds
+func feedLinks(body []byte, base *url.URL) []discoveredFeed {
+	var feeds []discoveredFeed
+	seen := map[string]bool{}
+
+	for _, tag := range linkTagPattern.FindAll(body, -1) {
+		attributes := map[string]string{}
+		for _, match := range attributePattern.FindAllSubmatch(tag, -1) {
+			value := string(match[2]) + string(match[3]) + string(match[4])
+			attributes[strings.ToLower(string(match[1]))] = html.UnescapeString(strings.TrimSpace(value))
+		}
+
+		isAlternate := false
+		for _, rel := range strings.Fields(strings.ToLower(attributes["rel"])) {
+			if rel == "alternate" {
+				isAlternate = true
+			}
+		}
+
+		linkType := strings.ToLower(strings.TrimSpace(strings.Split(attributes["type"], ";")[0]))
+		if !isAlternate || !feedLinkTypes[linkType] || attributes["href"] == "" {
+			continue
+		}
+
+		href, err := base.Parse(attributes["href"])
+		if err != nil || !validFeedURL(href.String()) || seen[href.String()] {
+			continue
+		}
+		seen[href.String()] = true
+
+		feeds = append(feeds, discoveredFeed{url: href.String(), title: attributes["title"]})
+	}
+
+	return feeds
+}
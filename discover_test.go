@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestFeedLinks(t *testing.T) {
+	base, err := url.Parse("https://example.com/blog/post")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		html string
+		want []discoveredFeed
+	}{
+		{
+			"relative and absolute links",
+			`<head>
+			<link rel="alternate" type="application/rss+xml" title="RSS" href="/feed.xml">
+			<link rel="alternate" type="application/atom+xml" title="Atom" href="https://feeds.example.com/atom">
+			</head>`,
+			[]discoveredFeed{{url: "https://example.com/feed.xml", title: "RSS"}, {url: "https://feeds.example.com/atom", title: "Atom"}},
+		},
+		{
+			"path relative to the page",
+			`<link rel="alternate" type="application/feed+json" href="feed.json">`,
+			[]discoveredFeed{{url: "https://example.com/blog/feed.json"}},
+		},
+		{
+			"attribute order, case and quoting",
+			`<LINK HREF='/rdf' TYPE="Application/RDF+XML; charset=utf-8" REL=alternate>`,
+			[]discoveredFeed{{url: "https://example.com/rdf"}},
+		},
+		{
+			"rel with several values",
+			`<link rel="home alternate" type="application/rss+xml" href="/rss">`,
+			[]discoveredFeed{{url: "https://example.com/rss"}},
+		},
+		{
+			"entities in href and title",
+			`<link rel="alternate" type="application/rss+xml" title="News &amp; Notes" href="/feed?a=1&amp;b=2">`,
+			[]discoveredFeed{{url: "https://example.com/feed?a=1&b=2", title: "News & Notes"}},
+		},
+		{
+			"duplicates are skipped",
+			`<link rel="alternate" type="application/rss+xml" href="/rss"><link rel="alternate" type="application/rss+xml" href="https://example.com/rss">`,
+			[]discoveredFeed{{url: "https://example.com/rss"}},
+		},
+		{
+			"links that are not feeds",
+			`<link rel="stylesheet" type="text/css" href="/style.css">
+			<link rel="alternate" type="text/html" hreflang="de" href="/de/">
+			<link rel="icon" type="application/rss+xml" href="/not-alternate">
+			<link rel="alternate" type="application/rss+xml">
+			<link rel="alternate" type="application/rss+xml" href="mailto:feed@example.com">`,
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := feedLinks([]byte(test.html), base)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("feedLinks() = %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
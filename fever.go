@@ -0,0 +1,357 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saifullah605/Gator/internal/database"
+)
+
+const (
+	feverAPIVersion = 3
+	feverItemsLimit = 50
+	// gator has no folders, every followed feed is in this one group
+	feverGroupID = 1
+)
+
+type feverGroup struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+type feverFeedsGroup struct {
+	GroupID int64  `json:"group_id"`
+	FeedIDs string `json:"feed_ids"`
+}
+
+type feverFeed struct {
+	ID                int64  `json:"id"`
+	FaviconID         int64  `json:"favicon_id"`
+	Title             string `json:"title"`
+	Url               string `json:"url"`
+	SiteUrl           string `json:"site_url"`
+	IsSpark           int    `json:"is_spark"`
+	LastUpdatedOnTime int64  `json:"last_updated_on_time"`
+}
+
+type feverItem struct {
+	ID            int64  `json:"id"`
+	FeedID        int64  `json:"feed_id"`
+	Title         string `json:"title"`
+	Author        string `json:"author"`
+	HTML          string `json:"html"`
+	Url           string `json:"url"`
+	IsSaved       int    `json:"is_saved"`
+	IsRead        int    `json:"is_read"`
+	CreatedOnTime int64  `json:"created_on_time"`
+}
+
+func feverBool(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func joinIDs(ids []int64) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.FormatInt(id, 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+// parseIDs reads the comma separated ids fever clients send in with_ids
+func parseIDs(value string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %v", part)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// formInt64 returns 0 for a missing value, like fever does for since_id and max_id
+func formInt64(form url.Values, name string) (int64, error) {
+	value := form.Get(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%v has to be a number", name)
+	}
+
+	return parsed, nil
+}
+
+// handleFever implements the fever api, the users api_key is md5 of "name:token" for one of their api tokens and only its hash is stored
+func (api *apiServer) handleFever(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondError(w, http.StatusBadRequest, "invalid form")
+		return
+	}
+	if !r.Form.Has("api") {
+		respondError(w, http.StatusBadRequest, "fever requests need the api parameter")
+		return
+	}
+
+	response := map[string]any{
+		"api_version": feverAPIVersion,
+		"auth":        0,
+	}
+
+	user, err := api.state.db.GetUserByFeverKey(r.Context(), sql.NullString{
+		String: hashAPIToken(strings.ToLower(r.Form.Get("api_key"))),
+		Valid:  true,
+	})
+	if err == sql.ErrNoRows {
+		respondJSON(w, http.StatusOK, response)
+		return
+	} else if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot get user info")
+		return
+	}
+	response["auth"] = 1
+
+	if r.Form.Get("mark") != "" {
+		if err := api.feverMark(r.Context(), user, r.Form); err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	feeds, err := api.state.db.GetFollowedFeedsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot get followed feeds")
+		return
+	}
+
+	var lastRefreshed int64
+	feedIDs := make([]int64, 0, len(feeds))
+	for _, feed := range feeds {
+		feedIDs = append(feedIDs, feed.SerialID)
+		if feed.LastFetchedAt.Valid {
+			lastRefreshed = max(lastRefreshed, feed.LastFetchedAt.Time.Unix())
+		}
+	}
+	response["last_refreshed_on_time"] = lastRefreshed
+
+	feedsGroups := []feverFeedsGroup{{GroupID: feverGroupID, FeedIDs: joinIDs(feedIDs)}}
+
+	if r.Form.Has("groups") {
+		response["groups"] = []feverGroup{{ID: feverGroupID, Title: "All"}}
+		response["feeds_groups"] = feedsGroups
+	}
+
+	if r.Form.Has("feeds") {
+		records := make([]feverFeed, 0, len(feeds))
+		for _, feed := range feeds {
+			var lastUpdated int64
+			if feed.LastFetchedAt.Valid {
+				lastUpdated = feed.LastFetchedAt.Time.Unix()
+			}
+
+			records = append(records, feverFeed{
+				ID:                feed.SerialID,
+				FaviconID:         0,
+				Title:             feed.Name,
+				Url:               feed.Url,
+				SiteUrl:           feed.SiteUrl.String,
+				LastUpdatedOnTime: lastUpdated,
+			})
+		}
+		response["feeds"] = records
+		response["feeds_groups"] = feedsGroups
+	}
+
+	if r.Form.Has("favicons") {
+		response["favicons"] = []struct{}{}
+	}
+
+	if r.Form.Has("links") {
+		response["links"] = []struct{}{}
+	}
+
+	if r.Form.Has("items") {
+		items, total, err := api.feverItems(r.Context(), user, r.Form)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response["items"] = items
+		response["total_items"] = total
+	}
+
+	if r.Form.Has("unread_item_ids") {
+		ids, err := api.state.db.GetUnreadPostSerialIds(r.Context(), user.ID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "cannot get unread items")
+			return
+		}
+		response["unread_item_ids"] = joinIDs(ids)
+	}
+
+	if r.Form.Has("saved_item_ids") {
+		ids, err := api.state.db.GetSavedPostSerialIds(r.Context(), user.ID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "cannot get saved items")
+			return
+		}
+		response["saved_item_ids"] = joinIDs(ids)
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+// feverItems returns up to 50 items after since_id, before max_id or in with_ids
+func (api *apiServer) feverItems(ctx context.Context, user database.User, form url.Values) ([]feverItem, int64, error) {
+	sinceID, err := formInt64(form, "since_id")
+	if err != nil {
+		return nil, 0, err
+	}
+	maxID, err := formInt64(form, "max_id")
+	if err != nil {
+		return nil, 0, err
+	}
+	withIDs, err := parseIDs(form.Get("with_ids"))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	posts, err := api.state.db.GetItemsForUser(ctx, database.GetItemsForUserParams{
+		UserID:  user.ID,
+		SinceID: sinceID,
+		MaxID:   maxID,
+		WithIds: withIDs,
+		Limit:   feverItemsLimit,
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot load items")
+	}
+
+	total, err := api.state.db.CountPostsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot count items")
+	}
+
+	items := make([]feverItem, 0, len(posts))
+	for _, post := range posts {
+		createdOn := post.CreatedAt
+		if post.PublishedAt.Valid {
+			createdOn = post.PublishedAt.Time
+		}
+
+		items = append(items, feverItem{
+			ID:            post.SerialID,
+			FeedID:        post.FeedSerialID,
+			Title:         post.Title.String,
+			HTML:          post.Description.String,
+			Url:           post.Url,
+			IsSaved:       feverBool(post.IsSaved),
+			IsRead:        feverBool(post.IsRead),
+			CreatedOnTime: createdOn.Unix(),
+		})
+	}
+
+	return items, total, nil
+}
+
+// feverMark handles mark=item|feed|group, feeds and groups can only be marked read
+func (api *apiServer) feverMark(ctx context.Context, user database.User, form url.Values) error {
+	id, err := formInt64(form, "id")
+	if err != nil {
+		return err
+	}
+
+	mark, as := form.Get("mark"), form.Get("as")
+
+	switch mark {
+	case "item":
+		postId, err := api.state.db.GetPostIdBySerialId(ctx, id)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("item %v does not exist", id)
+		} else if err != nil {
+			return fmt.Errorf("cannot look up item")
+		}
+
+		switch as {
+		case "read":
+			err = api.state.db.MarkPostRead(ctx, database.MarkPostReadParams{
+				UserID: user.ID,
+				PostID: postId,
+				ReadAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+			})
+		case "unread":
+			_, err = api.state.db.MarkPostUnread(ctx, database.MarkPostUnreadParams{
+				UserID: user.ID,
+				PostID: postId,
+			})
+		case "saved":
+			err = api.state.db.SavePost(ctx, database.SavePostParams{
+				UserID:    user.ID,
+				PostID:    postId,
+				CreatedAt: time.Now().UTC(),
+			})
+		case "unsaved":
+			_, err = api.state.db.UnsavePost(ctx, database.UnsavePostParams{
+				UserID: user.ID,
+				PostID: postId,
+			})
+		default:
+			return fmt.Errorf("items can be marked read, unread, saved or unsaved")
+		}
+
+		if err != nil {
+			return fmt.Errorf("cannot mark item %v", as)
+		}
+		return nil
+	case "feed", "group":
+		if as != "read" {
+			return fmt.Errorf("%v can only be marked read", mark)
+		}
+
+		before := time.Now().UTC()
+		if seconds, err := formInt64(form, "before"); err != nil {
+			return err
+		} else if seconds > 0 {
+			before = time.Unix(seconds, 0).UTC()
+		}
+
+		// group 0 is every feed in fever, and the only real group holds every feed too
+		feedSerialID := id
+		if mark == "group" {
+			feedSerialID = 0
+		} else if id == 0 {
+			return fmt.Errorf("need the id of the feed")
+		}
+
+		if _, err := api.state.db.MarkFeedPostsRead(ctx, database.MarkFeedPostsReadParams{
+			UserID:       user.ID,
+			ReadAt:       sql.NullTime{Time: time.Now().UTC(), Valid: true},
+			FeedSerialID: feedSerialID,
+			Before:       before,
+		}); err != nil {
+			return fmt.Errorf("cannot mark %v read", mark)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown mark %v, use item, feed or group", mark)
+	}
+}
@@ -0,0 +1,668 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saifullah605/Gator/internal/database"
+)
+
+const (
+	greaderItemPrefix  = "tag:google.com,2005:reader/item/"
+	greaderReadingList = "user/-/state/com.google/reading-list"
+	greaderRead        = "user/-/state/com.google/read"
+	greaderStarred     = "user/-/state/com.google/starred"
+	greaderMaxItems    = 10000
+)
+
+type greaderSubscription struct {
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Categories []string `json:"categories"`
+	Url        string   `json:"url"`
+	HTMLUrl    string   `json:"htmlUrl"`
+	IconUrl    string   `json:"iconUrl"`
+}
+
+type greaderLink struct {
+	Href string `json:"href"`
+	Type string `json:"type,omitempty"`
+}
+
+type greaderItem struct {
+	ID            string        `json:"id"`
+	CrawlTimeMsec string        `json:"crawlTimeMsec"`
+	TimestampUsec string        `json:"timestampUsec"`
+	Published     int64         `json:"published"`
+	Updated       int64         `json:"updated"`
+	Title         string        `json:"title"`
+	Canonical     []greaderLink `json:"canonical"`
+	Alternate     []greaderLink `json:"alternate"`
+	Summary       struct {
+		Content string `json:"content"`
+	} `json:"summary"`
+	Categories []string `json:"categories"`
+	Origin     struct {
+		StreamID string `json:"streamId"`
+		Title    string `json:"title"`
+		HTMLUrl  string `json:"htmlUrl"`
+	} `json:"origin"`
+	Author string `json:"author"`
+}
+
+type greaderItemRef struct {
+	ID              string   `json:"id"`
+	DirectStreamIDs []string `json:"directStreamIds"`
+	TimestampUsec   string   `json:"timestampUsec"`
+}
+
+// greaderState matches a state tag with or without the user id, clients send both user/-/ and user/<id>/
+func greaderState(tag, state string) bool {
+	return strings.HasPrefix(tag, "user/") && strings.HasSuffix(tag, "/state/com.google/"+state)
+}
+
+// parseItemID reads the long hex form and the short decimal form of an item id
+func parseItemID(value string) (int64, error) {
+	if hexID, ok := strings.CutPrefix(value, greaderItemPrefix); ok {
+		id, err := strconv.ParseUint(hexID, 16, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid item id %v", value)
+		}
+		return int64(id), nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid item id %v", value)
+	}
+	return id, nil
+}
+
+func respondText(w http.ResponseWriter, status int, text string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write([]byte(text))
+}
+
+func (api *apiServer) greaderRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/accounts/ClientLogin", api.handleClientLogin)
+	mux.HandleFunc("GET /reader/api/0/token", api.greaderAuthenticated(api.handleGreaderToken))
+	mux.HandleFunc("GET /reader/api/0/user-info", api.greaderAuthenticated(api.handleGreaderUserInfo))
+	mux.HandleFunc("GET /reader/api/0/tag/list", api.greaderAuthenticated(api.handleGreaderTags))
+	mux.HandleFunc("GET /reader/api/0/subscription/list", api.greaderAuthenticated(api.handleGreaderSubscriptions))
+	mux.HandleFunc("POST /reader/api/0/subscription/edit", api.greaderAuthenticated(api.handleGreaderEditSubscription))
+	mux.HandleFunc("POST /reader/api/0/subscription/quickadd", api.greaderAuthenticated(api.handleGreaderQuickAdd))
+	mux.HandleFunc("GET /reader/api/0/stream/contents", api.greaderAuthenticated(api.handleGreaderStreamContents))
+	mux.HandleFunc("GET /reader/api/0/stream/items/ids", api.greaderAuthenticated(api.handleGreaderItemIDs))
+	mux.HandleFunc("/reader/api/0/stream/items/contents", api.greaderAuthenticated(api.handleGreaderItemContents))
+	mux.HandleFunc("POST /reader/api/0/edit-tag", api.greaderAuthenticated(api.handleGreaderEditTag))
+	mux.HandleFunc("POST /reader/api/0/mark-all-as-read", api.greaderAuthenticated(api.handleGreaderMarkAllRead))
+}
+
+const greaderStreamPrefix = "/reader/api/0/stream/contents/"
+
+// greaderStreamPaths serves stream/contents/<stream id> before next, ServeMux would clean the // out of
+// unencoded feed ids like feed/https://host/rss and redirect the client to a stream that does not exist
+func (api *apiServer) greaderStreamPaths(next http.Handler) http.Handler {
+	streamContents := api.greaderAuthenticated(api.handleGreaderStreamContents)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream, ok := strings.CutPrefix(r.URL.EscapedPath(), greaderStreamPrefix)
+		if !ok || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		stream, err := url.PathUnescape(stream)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "invalid stream id")
+			return
+		}
+
+		r.SetPathValue("stream", stream)
+		streamContents(w, r)
+	})
+}
+
+// handleClientLogin takes the user name as Email and an api token as Passwd, the token is then used as the auth token
+func (api *apiServer) handleClientLogin(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		respondText(w, http.StatusBadRequest, "Error=BadRequest\n")
+		return
+	}
+
+	token := r.Form.Get("Passwd")
+	user, err := api.state.db.GetUserByApiToken(r.Context(), hashAPIToken(token))
+	if err == sql.ErrNoRows || (err == nil && user.Name != r.Form.Get("Email")) {
+		respondText(w, http.StatusUnauthorized, "Error=BadAuthentication\n")
+		return
+	} else if err != nil {
+		respondText(w, http.StatusInternalServerError, "Error=Unknown\n")
+		return
+	}
+
+	if r.Form.Get("output") == "json" {
+		respondJSON(w, http.StatusOK, map[string]string{"SID": token, "LSID": token, "Auth": token})
+		return
+	}
+
+	respondText(w, http.StatusOK, fmt.Sprintf("SID=%v\nLSID=%v\nAuth=%v\n", token, token, token))
+}
+
+// greaderAuthenticated identifies the user from the Authorization: GoogleLogin auth=<token> header
+func (api *apiServer) greaderAuthenticated(handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "GoogleLogin auth=")
+		if !ok || token == "" {
+			respondText(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+
+		user, err := api.state.db.GetUserByApiToken(r.Context(), hashAPIToken(strings.TrimSpace(token)))
+		if err == sql.ErrNoRows {
+			respondText(w, http.StatusUnauthorized, "Unauthorized")
+			return
+		} else if err != nil {
+			respondText(w, http.StatusInternalServerError, "cannot get user info")
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			respondText(w, http.StatusBadRequest, "invalid form")
+			return
+		}
+
+		handler(w, r, user)
+	}
+}
+
+// handleGreaderToken returns the edit token clients send back as T, edits are authenticated by the auth header so it is not checked
+func (api *apiServer) handleGreaderToken(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondText(w, http.StatusOK, strings.ReplaceAll(user.ID.String(), "-", ""))
+}
+
+func (api *apiServer) handleGreaderUserInfo(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondJSON(w, http.StatusOK, map[string]string{
+		"userId":        user.ID.String(),
+		"userName":      user.Name,
+		"userProfileId": user.ID.String(),
+		"userEmail":     "",
+	})
+}
+
+// handleGreaderTags only lists the starred state, gator has no folders or labels
+func (api *apiServer) handleGreaderTags(w http.ResponseWriter, r *http.Request, user database.User) {
+	respondJSON(w, http.StatusOK, map[string]any{
+		"tags": []map[string]string{{"id": greaderStarred}},
+	})
+}
+
+func (api *apiServer) handleGreaderSubscriptions(w http.ResponseWriter, r *http.Request, user database.User) {
+	feeds, err := api.state.db.GetFollowedFeedsForUser(r.Context(), user.ID)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot get followed feeds")
+		return
+	}
+
+	subscriptions := make([]greaderSubscription, 0, len(feeds))
+	for _, feed := range feeds {
+		subscriptions = append(subscriptions, greaderSubscription{
+			ID:         "feed/" + feed.Url,
+			Title:      feed.Name,
+			Categories: []string{},
+			Url:        feed.Url,
+			HTMLUrl:    feed.SiteUrl.String,
+			IconUrl:    feed.ImageUrl.String,
+		})
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{"subscriptions": subscriptions})
+}
+
+// subscribe follows the feed at feedURL, adding it first if no one has yet
+func (api *apiServer) subscribe(ctx context.Context, user database.User, feedURL, title string) (string, error) {
+	if !validFeedURL(feedURL) {
+		return "", fmt.Errorf("invalid feed url %v", feedURL)
+	}
+	if title == "" {
+		title = feedURL
+	}
+
+	feedId, err := api.state.db.GetFeedId(ctx, feedURL)
+	if err == sql.ErrNoRows {
+		feed, err := api.state.db.CreateFeed(ctx, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			Name:      title,
+			Url:       feedURL,
+			UserID:    user.ID,
+		})
+		if err != nil {
+			return "", fmt.Errorf("cannot add feed")
+		}
+		feedId = feed.ID
+	} else if err != nil {
+		return "", fmt.Errorf("cannot look up feed")
+	}
+
+	follow, err := api.state.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		FeedID:    feedId,
+	})
+	if isUniqueViolation(err) {
+		return title, nil
+	} else if err != nil {
+		return "", fmt.Errorf("cannot follow feed")
+	}
+
+	return follow.FeedName, nil
+}
+
+// handleGreaderEditSubscription subscribes and unsubscribes, renames and folders are accepted but ignored
+func (api *apiServer) handleGreaderEditSubscription(w http.ResponseWriter, r *http.Request, user database.User) {
+	action := r.Form.Get("ac")
+
+	for _, stream := range r.Form["s"] {
+		feedURL, ok := strings.CutPrefix(stream, "feed/")
+		if !ok {
+			respondError(w, http.StatusBadRequest, "subscriptions have to be feed/<url> streams")
+			return
+		}
+
+		switch action {
+		case "subscribe":
+			if _, err := api.subscribe(r.Context(), user, feedURL, r.Form.Get("t")); err != nil {
+				respondError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+		case "unsubscribe":
+			if _, err := api.state.db.Unfollow(r.Context(), database.UnfollowParams{
+				UserID: user.ID,
+				Url:    feedURL,
+			}); err != nil && err != sql.ErrNoRows {
+				respondError(w, http.StatusInternalServerError, "cannot unfollow feed")
+				return
+			}
+		case "edit":
+		default:
+			respondError(w, http.StatusBadRequest, "ac has to be subscribe, unsubscribe or edit")
+			return
+		}
+	}
+
+	respondText(w, http.StatusOK, "OK")
+}
+
+func (api *apiServer) handleGreaderQuickAdd(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedURL := strings.TrimPrefix(r.Form.Get("quickadd"), "feed/")
+
+	name, err := api.subscribe(r.Context(), user, feedURL, "")
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"numResults": 1,
+		"query":      feedURL,
+		"streamId":   "feed/" + feedURL,
+		"streamName": name,
+	})
+}
+
+// streamParams maps a stream id and the n, c, r, ot, nt, xt and it parameters onto GetStreamItemsForUser
+func streamParams(user database.User, stream string, form url.Values, defaultLimit int) (database.GetStreamItemsForUserParams, error) {
+	params := database.GetStreamItemsForUserParams{
+		UserID:      user.ID,
+		IncludeRead: true,
+		OldestFirst: form.Get("r") == "o",
+	}
+
+	switch {
+	case stream == "" || greaderState(stream, "reading-list"):
+	case greaderState(stream, "starred"):
+		params.SavedOnly = true
+	case greaderState(stream, "read"):
+		params.ReadOnly = true
+	case strings.HasPrefix(stream, "feed/"):
+		params.FeedUrl = sql.NullString{String: strings.TrimPrefix(stream, "feed/"), Valid: true}
+	default:
+		return params, fmt.Errorf("unsupported stream %v", stream)
+	}
+
+	for _, target := range form["xt"] {
+		if greaderState(target, "read") {
+			params.IncludeRead = false
+		}
+	}
+	for _, target := range form["it"] {
+		if greaderState(target, "read") {
+			params.ReadOnly = true
+		} else if greaderState(target, "starred") {
+			params.SavedOnly = true
+		}
+	}
+
+	limit := defaultLimit
+	if value := form.Get("n"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return params, fmt.Errorf("n has to be a positive number")
+		}
+		limit = min(parsed, greaderMaxItems)
+	}
+	params.Limit = int32(limit)
+
+	if value := form.Get("c"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			return params, fmt.Errorf("invalid continuation")
+		}
+		params.Offset = int32(offset)
+	}
+
+	for name, target := range map[string]*sql.NullTime{"ot": &params.Since, "nt": &params.Until} {
+		if value := form.Get(name); value != "" {
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return params, fmt.Errorf("%v has to be a unix timestamp", name)
+			}
+			*target = sql.NullTime{Time: time.Unix(seconds, 0).UTC(), Valid: true}
+		}
+	}
+
+	return params, nil
+}
+
+// continuation is the offset of the next page, empty when this page was the last
+func continuation(params database.GetStreamItemsForUserParams, count int) string {
+	if count < int(params.Limit) {
+		return ""
+	}
+	return strconv.Itoa(int(params.Offset) + count)
+}
+
+func newGreaderItem(post database.GetStreamItemsForUserRow) greaderItem {
+	published := post.CreatedAt
+	if post.PublishedAt.Valid {
+		published = post.PublishedAt.Time
+	}
+
+	item := greaderItem{
+		ID:            fmt.Sprintf("%v%016x", greaderItemPrefix, uint64(post.SerialID)),
+		CrawlTimeMsec: strconv.FormatInt(post.CreatedAt.UnixMilli(), 10),
+		TimestampUsec: strconv.FormatInt(published.UnixMicro(), 10),
+		Published:     published.Unix(),
+		Updated:       post.UpdatedAt.Unix(),
+		Title:         post.Title.String,
+		Canonical:     []greaderLink{{Href: post.Url}},
+		Alternate:     []greaderLink{{Href: post.Url, Type: "text/html"}},
+		Categories:    []string{greaderReadingList, "feed/" + post.FeedUrl},
+	}
+	item.Summary.Content = post.Description.String
+	item.Origin.StreamID = "feed/" + post.FeedUrl
+	item.Origin.Title = post.FeedName
+	item.Origin.HTMLUrl = post.FeedSiteUrl.String
+
+	if post.IsRead {
+		item.Categories = append(item.Categories, greaderRead)
+	}
+	if post.IsSaved {
+		item.Categories = append(item.Categories, greaderStarred)
+	}
+
+	return item
+}
+
+func (api *apiServer) handleGreaderStreamContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	stream := r.PathValue("stream")
+	if stream == "" {
+		stream = r.Form.Get("s")
+	}
+
+	params, err := streamParams(user, stream, r.Form, 20)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	posts, err := api.state.db.GetStreamItemsForUser(r.Context(), params)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot load items")
+		return
+	}
+
+	items := make([]greaderItem, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, newGreaderItem(post))
+	}
+
+	response := map[string]any{
+		"direction": "ltr",
+		"id":        stream,
+		"updated":   time.Now().UTC().Unix(),
+		"items":     items,
+	}
+	if next := continuation(params, len(posts)); next != "" {
+		response["continuation"] = next
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+func (api *apiServer) handleGreaderItemIDs(w http.ResponseWriter, r *http.Request, user database.User) {
+	params, err := streamParams(user, r.Form.Get("s"), r.Form, 1000)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	posts, err := api.state.db.GetStreamItemsForUser(r.Context(), params)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot load items")
+		return
+	}
+
+	refs := make([]greaderItemRef, 0, len(posts))
+	for _, post := range posts {
+		item := newGreaderItem(post)
+		refs = append(refs, greaderItemRef{
+			ID:              strconv.FormatInt(post.SerialID, 10),
+			DirectStreamIDs: []string{},
+			TimestampUsec:   item.TimestampUsec,
+		})
+	}
+
+	response := map[string]any{"itemRefs": refs}
+	if next := continuation(params, len(posts)); next != "" {
+		response["continuation"] = next
+	}
+
+	respondJSON(w, http.StatusOK, response)
+}
+
+func (api *apiServer) handleGreaderItemContents(w http.ResponseWriter, r *http.Request, user database.User) {
+	ids := make([]int64, 0, len(r.Form["i"]))
+	for _, value := range r.Form["i"] {
+		id, err := parseItemID(value)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	var posts []database.GetStreamItemsForUserRow
+	if len(ids) != 0 {
+		var err error
+		posts, err = api.state.db.GetStreamItemsForUser(r.Context(), database.GetStreamItemsForUserParams{
+			UserID:      user.ID,
+			IncludeRead: true,
+			WithIds:     ids,
+			Limit:       int32(len(ids)),
+		})
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "cannot load items")
+			return
+		}
+	}
+
+	items := make([]greaderItem, 0, len(posts))
+	for _, post := range posts {
+		items = append(items, newGreaderItem(post))
+	}
+
+	respondJSON(w, http.StatusOK, map[string]any{
+		"direction": "ltr",
+		"id":        greaderReadingList,
+		"updated":   time.Now().UTC().Unix(),
+		"items":     items,
+	})
+}
+
+// handleGreaderEditTag maps the read and starred states onto post_states and saved_posts
+func (api *apiServer) handleGreaderEditTag(w http.ResponseWriter, r *http.Request, user database.User) {
+	for _, value := range r.Form["i"] {
+		serialID, err := parseItemID(value)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		postId, err := api.state.db.GetPostIdBySerialId(r.Context(), serialID)
+		if err == sql.ErrNoRows {
+			respondError(w, http.StatusNotFound, fmt.Sprintf("item %v does not exist", value))
+			return
+		} else if err != nil {
+			respondError(w, http.StatusInternalServerError, "cannot look up item")
+			return
+		}
+
+		if err := api.editTags(r.Context(), user, postId, r.Form["a"], r.Form["r"]); err != nil {
+			respondError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	respondText(w, http.StatusOK, "OK")
+}
+
+func (api *apiServer) editTags(ctx context.Context, user database.User, postId uuid.UUID, add, remove []string) error {
+	markRead := func() error {
+		return api.state.db.MarkPostRead(ctx, database.MarkPostReadParams{
+			UserID: user.ID,
+			PostID: postId,
+			ReadAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		})
+	}
+	markUnread := func() error {
+		_, err := api.state.db.MarkPostUnread(ctx, database.MarkPostUnreadParams{
+			UserID: user.ID,
+			PostID: postId,
+		})
+		return err
+	}
+	save := func() error {
+		return api.state.db.SavePost(ctx, database.SavePostParams{
+			UserID:    user.ID,
+			PostID:    postId,
+			CreatedAt: time.Now().UTC(),
+		})
+	}
+	unsave := func() error {
+		_, err := api.state.db.UnsavePost(ctx, database.UnsavePostParams{
+			UserID: user.ID,
+			PostID: postId,
+		})
+		return err
+	}
+
+	for _, tag := range add {
+		var err error
+		switch {
+		case greaderState(tag, "read"):
+			err = markRead()
+		case greaderState(tag, "kept-unread"):
+			err = markUnread()
+		case greaderState(tag, "starred"):
+			err = save()
+		}
+		if err != nil {
+			return fmt.Errorf("cannot add %v", tag)
+		}
+	}
+
+	for _, tag := range remove {
+		var err error
+		switch {
+		case greaderState(tag, "read"):
+			err = markUnread()
+		case greaderState(tag, "starred"):
+			err = unsave()
+		}
+		if err != nil {
+			return fmt.Errorf("cannot remove %v", tag)
+		}
+	}
+
+	return nil
+}
+
+// handleGreaderMarkAllRead marks a feed or the whole reading list read, up to ts in microseconds when given
+func (api *apiServer) handleGreaderMarkAllRead(w http.ResponseWriter, r *http.Request, user database.User) {
+	before := time.Now().UTC()
+	if value := r.Form.Get("ts"); value != "" {
+		usec, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			respondError(w, http.StatusBadRequest, "ts has to be a timestamp in microseconds")
+			return
+		}
+		before = time.UnixMicro(usec)
+	}
+
+	var feedSerialID int64
+	stream := r.Form.Get("s")
+
+	if feedURL, ok := strings.CutPrefix(stream, "feed/"); ok {
+		feeds, err := api.state.db.GetFollowedFeedsForUser(r.Context(), user.ID)
+		if err != nil {
+			respondError(w, http.StatusInternalServerError, "cannot get followed feeds")
+			return
+		}
+		for _, feed := range feeds {
+			if feed.Url == feedURL {
+				feedSerialID = feed.SerialID
+			}
+		}
+		if feedSerialID == 0 {
+			respondError(w, http.StatusNotFound, "feed is not followed")
+			return
+		}
+	} else if !greaderState(stream, "reading-list") {
+		respondError(w, http.StatusBadRequest, fmt.Sprintf("unsupported stream %v", stream))
+		return
+	}
+
+	if _, err := api.state.db.MarkFeedPostsRead(r.Context(), database.MarkFeedPostsReadParams{
+		UserID:       user.ID,
+		ReadAt:       sql.NullTime{Time: time.Now().UTC(), Valid: true},
+		FeedSerialID: feedSerialID,
+		Before:       before,
+	}); err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot mark posts read")
+		return
+	}
+
+	respondText(w, http.StatusOK, "OK")
+}
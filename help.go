@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+func (c *commands) names() []string {
+	names := make([]string, 0, len(c.cmds))
+	for name := range c.cmds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (c *commands) handlerHelp(s *state, cmd command) error {
+	if len(cmd.arguments) != 0 {
+		return c.printCommandHelp(cmd.arguments[0])
+	}
+
+	c.printHelp()
+	return nil
+}
+
+func (c *commands) printHelp() {
+	fmt.Println("usage: gator [--output json|csv|table] <command> [arguments]")
+	fmt.Println()
+	fmt.Println("commands:")
+
+	width := 0
+	for name := range c.cmds {
+		width = max(width, len(name))
+	}
+
+	for _, name := range c.names() {
+		fmt.Printf("  %-*v  %v\n", width, name, c.cmds[name].description)
+	}
+
+	fmt.Println()
+	fmt.Println("use help <command> for the usage and flags of a command")
+}
+
+func (c *commands) printCommandHelp(name string) error {
+	info, ok := c.cmds[name]
+	if !ok {
+		if suggestion := c.suggest(name); suggestion != "" {
+			return fmt.Errorf("command %v does not exist, did you mean %v?", name, suggestion)
+		}
+		return fmt.Errorf("command %v does not exist, use help to list the commands", name)
+	}
+
+	fmt.Println("usage:", info.usage)
+	fmt.Println()
+	fmt.Println(info.description)
+
+	flags := info.flagSet(name)
+	hasFlags := false
+	flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Println()
+		fmt.Println("flags:")
+		flags.SetOutput(os.Stdout)
+		flags.PrintDefaults()
+	}
+
+	return nil
+}
+
+// suggest returns the registered command closest to name, or "" when nothing is close enough
+func (c *commands) suggest(name string) string {
+	return closestName(name, c.names())
+}
+
+// suggestFlag returns the flag of a command closest to a mistyped flag name
+func suggestFlag(flags *flag.FlagSet, name string) string {
+	var names []string
+	flags.VisitAll(func(f *flag.Flag) { names = append(names, f.Name) })
+
+	return closestName(name, names)
+}
+
+// closestName returns the candidate name is a prefix of or a typo of, or "" when nothing is close enough
+func closestName(name string, candidates []string) string {
+	best := ""
+	bestDistance := 3
+
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, name) && len(name) >= 3 {
+			return candidate
+		}
+
+		if distance := editDistance(name, candidate); distance < bestDistance {
+			best = candidate
+			bestDistance = distance
+		}
+	}
+
+	return best
+}
+
+// editDistance is the levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
@@ -13,6 +13,55 @@ import (
 	"github.com/google/uuid"
 )
 
+const claimNextFeedToFetch = `-- name: ClaimNextFeedToFetch :one
+UPDATE feeds SET last_fetched_at = $1, updated_at = $1
+WHERE id = (
+     SELECT id FROM feeds
+     WHERE (last_fetched_at IS NULL OR last_fetched_at < $2)
+          AND NOT disabled
+          AND (next_fetch_at IS NULL OR next_fetch_at <= $1)
+     ORDER BY last_fetched_at ASC NULLS FIRST
+     LIMIT 1
+     FOR UPDATE SKIP LOCKED
+)
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, fetch_failures, last_fetch_error, next_fetch_at, disabled, last_success_at, last_http_status, site_url, serial_id, title, description, language, image_url, generator
+`
+
+type ClaimNextFeedToFetchParams struct {
+	FetchedAt   sql.NullTime
+	StaleBefore sql.NullTime
+}
+
+func (q *Queries) ClaimNextFeedToFetch(ctx context.Context, arg ClaimNextFeedToFetchParams) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, claimNextFeedToFetch, arg.FetchedAt, arg.StaleBefore)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.NextFetchAt,
+		&i.Disabled,
+		&i.LastSuccessAt,
+		&i.LastHttpStatus,
+		&i.SiteUrl,
+		&i.SerialID,
+		&i.Title,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
+	)
+	return i, err
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
 VALUES (
@@ -24,7 +73,7 @@ VALUES (
      $6
 
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at, etag, last_modified, fetch_failures, last_fetch_error, next_fetch_at, disabled, last_success_at, last_http_status, site_url, serial_id, title, description, language, image_url, generator
 `
 
 type CreateFeedParams struct {
@@ -54,6 +103,21 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.Etag,
+		&i.LastModified,
+		&i.FetchFailures,
+		&i.LastFetchError,
+		&i.NextFetchAt,
+		&i.Disabled,
+		&i.LastSuccessAt,
+		&i.LastHttpStatus,
+		&i.SiteUrl,
+		&i.SerialID,
+		&i.Title,
+		&i.Description,
+		&i.Language,
+		&i.ImageUrl,
+		&i.Generator,
 	)
 	return i, err
 }
@@ -118,8 +182,21 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 	return i, err
 }
 
+const enableFeed = `-- name: EnableFeed :execrows
+UPDATE feeds SET disabled = FALSE, fetch_failures = 0, next_fetch_at = NULL, last_fetch_error = NULL
+WHERE url = $1
+`
+
+func (q *Queries) EnableFeed(ctx context.Context, url string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, enableFeed, url)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
-SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feeds.name as feed_name, users.name as user_name FROM feed_follows INNER JOIN feeds ON feed_follows.feed_id = feeds.id
+SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feeds.name as feed_name, feeds.url as feed_url, users.name as user_name FROM feed_follows INNER JOIN feeds ON feed_follows.feed_id = feeds.id
 INNER JOIN users ON feed_follows.user_id = users.id
 WHERE feed_follows.user_id = $1
 `
@@ -131,6 +208,7 @@ type GetFeedFollowsForUserRow struct {
 	UserID    uuid.UUID
 	FeedID    uuid.UUID
 	FeedName  string
+	FeedUrl   string
 	UserName  string
 }
 
@@ -150,6 +228,7 @@ func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (
 			&i.UserID,
 			&i.FeedID,
 			&i.FeedName,
+			&i.FeedUrl,
 			&i.UserName,
 		); err != nil {
 			return nil, err
@@ -177,15 +256,99 @@ func (q *Queries) GetFeedId(ctx context.Context, url string) (uuid.UUID, error)
 	return id, err
 }
 
+const getFeedStatuses = `-- name: GetFeedStatuses :many
+SELECT feeds.id, feeds.name, feeds.url, feeds.last_fetched_at, feeds.last_success_at, feeds.last_http_status,
+     feeds.last_fetch_error, feeds.fetch_failures, feeds.disabled,
+     COUNT(posts.id) AS item_count,
+     COALESCE(
+          EXTRACT(EPOCH FROM (MAX(COALESCE(posts.published_at, posts.created_at)) - MIN(COALESCE(posts.published_at, posts.created_at))))
+          / NULLIF(COUNT(posts.id) - 1, 0),
+          0
+     )::float8 AS avg_seconds_between_posts
+FROM feeds
+LEFT JOIN posts ON posts.feed_id = feeds.id
+GROUP BY feeds.id
+ORDER BY feeds.name
+`
+
+type GetFeedStatusesRow struct {
+	ID                     uuid.UUID
+	Name                   string
+	Url                    string
+	LastFetchedAt          sql.NullTime
+	LastSuccessAt          sql.NullTime
+	LastHttpStatus         sql.NullInt32
+	LastFetchError         sql.NullString
+	FetchFailures          int32
+	Disabled               bool
+	ItemCount              int64
+	AvgSecondsBetweenPosts float64
+}
+
+func (q *Queries) GetFeedStatuses(ctx context.Context) ([]GetFeedStatusesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedStatuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedStatusesRow
+	for rows.Next() {
+		var i GetFeedStatusesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.LastFetchedAt,
+			&i.LastSuccessAt,
+			&i.LastHttpStatus,
+			&i.LastFetchError,
+			&i.FetchFailures,
+			&i.Disabled,
+			&i.ItemCount,
+			&i.AvgSecondsBetweenPosts,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeeds = `-- name: GetFeeds :many
-SELECT feeds.name, feeds.url, users.name as user FROM feeds
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.etag, feeds.last_modified, feeds.fetch_failures, feeds.last_fetch_error, feeds.next_fetch_at, feeds.disabled, feeds.last_success_at, feeds.last_http_status, feeds.site_url, feeds.serial_id, feeds.title, feeds.description, feeds.language, feeds.image_url, feeds.generator, users.name as user FROM feeds
 INNER JOIN users ON feeds.user_id = users.id
 `
 
 type GetFeedsRow struct {
-	Name string
-	Url  string
-	User string
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Name           string
+	Url            string
+	UserID         uuid.UUID
+	LastFetchedAt  sql.NullTime
+	Etag           sql.NullString
+	LastModified   sql.NullString
+	FetchFailures  int32
+	LastFetchError sql.NullString
+	NextFetchAt    sql.NullTime
+	Disabled       bool
+	LastSuccessAt  sql.NullTime
+	LastHttpStatus sql.NullInt32
+	SiteUrl        sql.NullString
+	SerialID       int64
+	Title          sql.NullString
+	Description    sql.NullString
+	Language       sql.NullString
+	ImageUrl       sql.NullString
+	Generator      sql.NullString
+	User           string
 }
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
@@ -197,7 +360,31 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 	var items []GetFeedsRow
 	for rows.Next() {
 		var i GetFeedsRow
-		if err := rows.Scan(&i.Name, &i.Url, &i.User); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.FetchFailures,
+			&i.LastFetchError,
+			&i.NextFetchAt,
+			&i.Disabled,
+			&i.LastSuccessAt,
+			&i.LastHttpStatus,
+			&i.SiteUrl,
+			&i.SerialID,
+			&i.Title,
+			&i.Description,
+			&i.Language,
+			&i.ImageUrl,
+			&i.Generator,
+			&i.User,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -211,36 +398,236 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 	return items, nil
 }
 
-const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds ORDER BY last_fetched_at ASC NULLS FIRST LIMIT 1
+const getFollowedFeedsForUser = `-- name: GetFollowedFeedsForUser :many
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.etag, feeds.last_modified, feeds.fetch_failures, feeds.last_fetch_error, feeds.next_fetch_at, feeds.disabled, feeds.last_success_at, feeds.last_http_status, feeds.site_url, feeds.serial_id, feeds.title, feeds.description, feeds.language, feeds.image_url, feeds.generator FROM feeds
+INNER JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
-	var i Feed
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Url,
-		&i.UserID,
-		&i.LastFetchedAt,
+func (q *Queries) GetFollowedFeedsForUser(ctx context.Context, userID uuid.UUID) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowedFeedsForUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.FetchFailures,
+			&i.LastFetchError,
+			&i.NextFetchAt,
+			&i.Disabled,
+			&i.LastSuccessAt,
+			&i.LastHttpStatus,
+			&i.SiteUrl,
+			&i.SerialID,
+			&i.Title,
+			&i.Description,
+			&i.Language,
+			&i.ImageUrl,
+			&i.Generator,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeedFollowsForUser = `-- name: ListFeedFollowsForUser :many
+SELECT feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id, feeds.name as feed_name, feeds.url as feed_url, users.name as user_name FROM feed_follows INNER JOIN feeds ON feed_follows.feed_id = feeds.id
+INNER JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name LIMIT $2 OFFSET $3
+`
+
+type ListFeedFollowsForUserParams struct {
+	UserID uuid.UUID
+	Limit  int32
+	Offset int32
+}
+
+type ListFeedFollowsForUserRow struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.UUID
+	FeedName  string
+	FeedUrl   string
+	UserName  string
+}
+
+func (q *Queries) ListFeedFollowsForUser(ctx context.Context, arg ListFeedFollowsForUserParams) ([]ListFeedFollowsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedFollowsForUser, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFeedFollowsForUserRow
+	for rows.Next() {
+		var i ListFeedFollowsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.UserName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeeds = `-- name: ListFeeds :many
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.etag, feeds.last_modified, feeds.fetch_failures, feeds.last_fetch_error, feeds.next_fetch_at, feeds.disabled, feeds.last_success_at, feeds.last_http_status, feeds.site_url, feeds.serial_id, feeds.title, feeds.description, feeds.language, feeds.image_url, feeds.generator, users.name as user FROM feeds
+INNER JOIN users ON feeds.user_id = users.id
+ORDER BY feeds.name LIMIT $1 OFFSET $2
+`
+
+type ListFeedsParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type ListFeedsRow struct {
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Name           string
+	Url            string
+	UserID         uuid.UUID
+	LastFetchedAt  sql.NullTime
+	Etag           sql.NullString
+	LastModified   sql.NullString
+	FetchFailures  int32
+	LastFetchError sql.NullString
+	NextFetchAt    sql.NullTime
+	Disabled       bool
+	LastSuccessAt  sql.NullTime
+	LastHttpStatus sql.NullInt32
+	SiteUrl        sql.NullString
+	SerialID       int64
+	Title          sql.NullString
+	Description    sql.NullString
+	Language       sql.NullString
+	ImageUrl       sql.NullString
+	Generator      sql.NullString
+	User           string
+}
+
+func (q *Queries) ListFeeds(ctx context.Context, arg ListFeedsParams) ([]ListFeedsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFeeds, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFeedsRow
+	for rows.Next() {
+		var i ListFeedsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.Etag,
+			&i.LastModified,
+			&i.FetchFailures,
+			&i.LastFetchError,
+			&i.NextFetchAt,
+			&i.Disabled,
+			&i.LastSuccessAt,
+			&i.LastHttpStatus,
+			&i.SiteUrl,
+			&i.SerialID,
+			&i.Title,
+			&i.Description,
+			&i.Language,
+			&i.ImageUrl,
+			&i.Generator,
+			&i.User,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const recordFeedFailure = `-- name: RecordFeedFailure :exec
+UPDATE feeds SET fetch_failures = fetch_failures + 1, last_fetch_error = $2, next_fetch_at = $3, disabled = $4, last_http_status = $5
+WHERE id = $1
+`
+
+type RecordFeedFailureParams struct {
+	ID             uuid.UUID
+	LastFetchError sql.NullString
+	NextFetchAt    sql.NullTime
+	Disabled       bool
+	LastHttpStatus sql.NullInt32
+}
+
+func (q *Queries) RecordFeedFailure(ctx context.Context, arg RecordFeedFailureParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedFailure,
+		arg.ID,
+		arg.LastFetchError,
+		arg.NextFetchAt,
+		arg.Disabled,
+		arg.LastHttpStatus,
 	)
-	return i, err
+	return err
 }
 
-const markFeedFetched = `-- name: MarkFeedFetched :exec
-UPDATE feeds SET last_fetched_at = $1, updated_at = $1 WHERE id = $2
+const recordFeedSuccess = `-- name: RecordFeedSuccess :exec
+UPDATE feeds SET fetch_failures = 0, last_fetch_error = NULL, next_fetch_at = NULL, last_success_at = $2, last_http_status = $3
+WHERE id = $1
 `
 
-type MarkFeedFetchedParams struct {
-	LastFetchedAt sql.NullTime
-	ID            uuid.UUID
+type RecordFeedSuccessParams struct {
+	ID             uuid.UUID
+	LastSuccessAt  sql.NullTime
+	LastHttpStatus sql.NullInt32
 }
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, arg MarkFeedFetchedParams) error {
-	_, err := q.db.ExecContext(ctx, markFeedFetched, arg.LastFetchedAt, arg.ID)
+func (q *Queries) RecordFeedSuccess(ctx context.Context, arg RecordFeedSuccessParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedSuccess, arg.ID, arg.LastSuccessAt, arg.LastHttpStatus)
 	return err
 }
 
@@ -268,3 +655,51 @@ func (q *Queries) Unfollow(ctx context.Context, arg UnfollowParams) (FeedFollow,
 	)
 	return i, err
 }
+
+const updateFeedCache = `-- name: UpdateFeedCache :exec
+UPDATE feeds SET etag = $1, last_modified = $2 WHERE id = $3
+`
+
+type UpdateFeedCacheParams struct {
+	Etag         sql.NullString
+	LastModified sql.NullString
+	ID           uuid.UUID
+}
+
+func (q *Queries) UpdateFeedCache(ctx context.Context, arg UpdateFeedCacheParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedCache, arg.Etag, arg.LastModified, arg.ID)
+	return err
+}
+
+const updateFeedMetadata = `-- name: UpdateFeedMetadata :exec
+UPDATE feeds SET site_url = COALESCE($1, site_url),
+     title = $2,
+     description = $3,
+     language = $4,
+     image_url = $5,
+     generator = $6
+WHERE id = $7
+`
+
+type UpdateFeedMetadataParams struct {
+	SiteUrl     sql.NullString
+	Title       sql.NullString
+	Description sql.NullString
+	Language    sql.NullString
+	ImageUrl    sql.NullString
+	Generator   sql.NullString
+	ID          uuid.UUID
+}
+
+func (q *Queries) UpdateFeedMetadata(ctx context.Context, arg UpdateFeedMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedMetadata,
+		arg.SiteUrl,
+		arg.Title,
+		arg.Description,
+		arg.Language,
+		arg.ImageUrl,
+		arg.Generator,
+		arg.ID,
+	)
+	return err
+}
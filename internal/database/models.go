@@ -11,14 +11,40 @@ import (
 	"github.com/google/uuid"
 )
 
+type ApiToken struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UserID     uuid.UUID
+	Name       string
+	TokenHash  string
+	LastUsedAt sql.NullTime
+	FeverKey   sql.NullString
+	Scope      string
+}
+
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID             uuid.UUID
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	Name           string
+	Url            string
+	UserID         uuid.UUID
+	LastFetchedAt  sql.NullTime
+	Etag           sql.NullString
+	LastModified   sql.NullString
+	FetchFailures  int32
+	LastFetchError sql.NullString
+	NextFetchAt    sql.NullTime
+	Disabled       bool
+	LastSuccessAt  sql.NullTime
+	LastHttpStatus sql.NullInt32
+	SiteUrl        sql.NullString
+	SerialID       int64
+	Title          sql.NullString
+	Description    sql.NullString
+	Language       sql.NullString
+	ImageUrl       sql.NullString
+	Generator      sql.NullString
 }
 
 type FeedFollow struct {
@@ -38,6 +64,21 @@ type Post struct {
 	Description sql.NullString
 	PublishedAt sql.NullTime
 	FeedID      uuid.UUID
+	SerialID    int64
+	Guid        string
+}
+
+type PostState struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	Read   bool
+	ReadAt sql.NullTime
+}
+
+type SavedPost struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
 }
 
 type User struct {
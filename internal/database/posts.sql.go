@@ -11,72 +11,312 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1,
-     $2,
-     $3,
-     $4,
-     $5,
-     $6,
-     $7,
-     $8
-     )
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+const adoptPostGuid = `-- name: AdoptPostGuid :execrows
+UPDATE posts SET guid = $1
+WHERE feed_id = $2 AND url = $3 AND guid = url AND guid <> $1
+AND NOT EXISTS (
+    SELECT 1 FROM posts AS existing
+    WHERE existing.feed_id = $2 AND existing.guid = $1
+)
 `
 
-type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       sql.NullString
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+type AdoptPostGuidParams struct {
+	Guid   string
+	FeedID uuid.UUID
+	Url    string
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
-	row := q.db.QueryRowContext(ctx, createPost,
-		arg.ID,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.Title,
-		arg.Url,
-		arg.Description,
-		arg.PublishedAt,
-		arg.FeedID,
-	)
-	var i Post
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Title,
-		&i.Url,
-		&i.Description,
-		&i.PublishedAt,
-		&i.FeedID,
+func (q *Queries) AdoptPostGuid(ctx context.Context, arg AdoptPostGuidParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, adoptPostGuid, arg.Guid, arg.FeedID, arg.Url)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countPostsForUser = `-- name: CountPostsForUser :one
+SELECT COUNT(*) FROM posts WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = $1
+)
+`
+
+func (q *Queries) CountPostsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsForUser, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getItemsForUser = `-- name: GetItemsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.serial_id, posts.guid, feeds.serial_id AS feed_serial_id,
+    EXISTS (
+        SELECT 1 FROM post_states
+        WHERE post_states.post_id = posts.id AND post_states.user_id = $1 AND post_states.read
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = $1
+    ) AS is_saved
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = $1
+)
+AND posts.serial_id > $2::bigint
+AND ($3::bigint = 0 OR posts.serial_id < $3::bigint)
+AND (cardinality($4::bigint[]) = 0 OR posts.serial_id = ANY($4::bigint[]))
+ORDER BY
+    CASE WHEN $3::bigint = 0 THEN posts.serial_id END ASC,
+    posts.serial_id DESC
+LIMIT $5
+`
+
+type GetItemsForUserParams struct {
+	UserID  uuid.UUID
+	SinceID int64
+	MaxID   int64
+	WithIds []int64
+	Limit   int32
+}
+
+type GetItemsForUserRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        sql.NullString
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	SerialID     int64
+	Guid         string
+	FeedSerialID int64
+	IsRead       bool
+	IsSaved      bool
+}
+
+func (q *Queries) GetItemsForUser(ctx context.Context, arg GetItemsForUserParams) ([]GetItemsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getItemsForUser,
+		arg.UserID,
+		arg.SinceID,
+		arg.MaxID,
+		pq.Array(arg.WithIds),
+		arg.Limit,
 	)
-	return i, err
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetItemsForUserRow
+	for rows.Next() {
+		var i GetItemsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SerialID,
+			&i.Guid,
+			&i.FeedSerialID,
+			&i.IsRead,
+			&i.IsSaved,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostIdBySerialId = `-- name: GetPostIdBySerialId :one
+SELECT id FROM posts WHERE serial_id = $1
+`
+
+func (q *Queries) GetPostIdBySerialId(ctx context.Context, serialID int64) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, getPostIdBySerialId, serialID)
+	var id uuid.UUID
+	err := row.Scan(&id)
+	return id, err
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id FROM posts WHERE posts.feed_id IN(
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.serial_id, posts.guid FROM posts WHERE posts.feed_id IN(
     SELECT feed_id FROM feed_follows
     WHERE user_id = $1
-) ORDER BY COALESCE(posts.published_at, posts.created_at) DESC LIMIT $2
+) AND ($2::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_states
+    WHERE post_states.post_id = posts.id AND post_states.user_id = $1 AND post_states.read
+)) ORDER BY COALESCE(posts.published_at, posts.created_at) DESC LIMIT $3
 `
 
 type GetPostsForUserParams struct {
-	UserID uuid.UUID
-	Limit  int32
+	UserID      uuid.UUID
+	IncludeRead bool
+	Limit       int32
 }
 
 func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.IncludeRead, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SerialID,
+			&i.Guid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserFiltered = `-- name: GetPostsForUserFiltered :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.serial_id, posts.guid FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = $1
+) AND ($2::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_states
+    WHERE post_states.post_id = posts.id AND post_states.user_id = $1 AND post_states.read
+))
+AND ($3::text IS NULL OR feeds.url = $3 OR feeds.name = $3)
+AND ($4::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) >= $4)
+AND ($5::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) < $5)
+ORDER BY
+    CASE WHEN $6::boolean THEN COALESCE(posts.published_at, posts.created_at) END ASC,
+    CASE WHEN NOT $6::boolean THEN COALESCE(posts.published_at, posts.created_at) END DESC,
+    posts.id
+LIMIT $7 OFFSET $8
+`
+
+type GetPostsForUserFilteredParams struct {
+	UserID      uuid.UUID
+	IncludeRead bool
+	Feed        sql.NullString
+	Since       sql.NullTime
+	Until       sql.NullTime
+	OldestFirst bool
+	Limit       int32
+	Offset      int32
+}
+
+func (q *Queries) GetPostsForUserFiltered(ctx context.Context, arg GetPostsForUserFilteredParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserFiltered,
+		arg.UserID,
+		arg.IncludeRead,
+		arg.Feed,
+		arg.Since,
+		arg.Until,
+		arg.OldestFirst,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SerialID,
+			&i.Guid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSavedPostSerialIds = `-- name: GetSavedPostSerialIds :many
+SELECT posts.serial_id FROM posts
+INNER JOIN saved_posts ON saved_posts.post_id = posts.id
+WHERE saved_posts.user_id = $1
+ORDER BY posts.serial_id
+`
+
+func (q *Queries) GetSavedPostSerialIds(ctx context.Context, userID uuid.UUID) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getSavedPostSerialIds, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var serial_id int64
+		if err := rows.Scan(&serial_id); err != nil {
+			return nil, err
+		}
+		items = append(items, serial_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSavedPostsForUser = `-- name: GetSavedPostsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.serial_id, posts.guid FROM posts
+INNER JOIN saved_posts ON saved_posts.post_id = posts.id
+WHERE saved_posts.user_id = $1
+ORDER BY saved_posts.created_at DESC
+`
+
+func (q *Queries) GetSavedPostsForUser(ctx context.Context, userID uuid.UUID) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getSavedPostsForUser, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +333,335 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.SerialID,
+			&i.Guid,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStreamItemsForUser = `-- name: GetStreamItemsForUser :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.serial_id, posts.guid, feeds.url AS feed_url, feeds.name AS feed_name, feeds.site_url AS feed_site_url,
+    EXISTS (
+        SELECT 1 FROM post_states
+        WHERE post_states.post_id = posts.id AND post_states.user_id = $1 AND post_states.read
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = $1
+    ) AS is_saved
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = $1
+) AND ($2::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_states
+    WHERE post_states.post_id = posts.id AND post_states.user_id = $1 AND post_states.read
+))
+AND ($3::text IS NULL OR feeds.url = $3)
+AND (NOT $4::boolean OR EXISTS (
+    SELECT 1 FROM saved_posts
+    WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = $1
+))
+AND (NOT $5::boolean OR EXISTS (
+    SELECT 1 FROM post_states
+    WHERE post_states.post_id = posts.id AND post_states.user_id = $1 AND post_states.read
+))
+AND ($6::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) >= $6)
+AND ($7::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) < $7)
+AND (cardinality($8::bigint[]) = 0 OR posts.serial_id = ANY($8::bigint[]))
+ORDER BY
+    CASE WHEN $9::boolean THEN COALESCE(posts.published_at, posts.created_at) END ASC,
+    CASE WHEN NOT $9::boolean THEN COALESCE(posts.published_at, posts.created_at) END DESC,
+    posts.serial_id
+LIMIT $10 OFFSET $11
+`
+
+type GetStreamItemsForUserParams struct {
+	UserID      uuid.UUID
+	IncludeRead bool
+	FeedUrl     sql.NullString
+	SavedOnly   bool
+	ReadOnly    bool
+	Since       sql.NullTime
+	Until       sql.NullTime
+	WithIds     []int64
+	OldestFirst bool
+	Limit       int32
+	Offset      int32
+}
+
+type GetStreamItemsForUserRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       sql.NullString
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedID      uuid.UUID
+	SerialID    int64
+	Guid        string
+	FeedUrl     string
+	FeedName    string
+	FeedSiteUrl sql.NullString
+	IsRead      bool
+	IsSaved     bool
+}
+
+func (q *Queries) GetStreamItemsForUser(ctx context.Context, arg GetStreamItemsForUserParams) ([]GetStreamItemsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getStreamItemsForUser,
+		arg.UserID,
+		arg.IncludeRead,
+		arg.FeedUrl,
+		arg.SavedOnly,
+		arg.ReadOnly,
+		arg.Since,
+		arg.Until,
+		pq.Array(arg.WithIds),
+		arg.OldestFirst,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetStreamItemsForUserRow
+	for rows.Next() {
+		var i GetStreamItemsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.SerialID,
+			&i.Guid,
+			&i.FeedUrl,
+			&i.FeedName,
+			&i.FeedSiteUrl,
+			&i.IsRead,
+			&i.IsSaved,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnreadPostSerialIds = `-- name: GetUnreadPostSerialIds :many
+SELECT posts.serial_id FROM posts WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = $1
+) AND NOT EXISTS (
+    SELECT 1 FROM post_states
+    WHERE post_states.post_id = posts.id AND post_states.user_id = $1 AND post_states.read
+) ORDER BY posts.serial_id
+`
+
+func (q *Queries) GetUnreadPostSerialIds(ctx context.Context, userID uuid.UUID) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreadPostSerialIds, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var serial_id int64
+		if err := rows.Scan(&serial_id); err != nil {
+			return nil, err
+		}
+		items = append(items, serial_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markAllPostsRead = `-- name: MarkAllPostsRead :execrows
+INSERT INTO post_states (user_id, post_id, read, read_at)
+SELECT $1, posts.id, TRUE, $2 FROM posts WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = $1
+)
+ON CONFLICT (user_id, post_id) DO UPDATE SET read = TRUE, read_at = EXCLUDED.read_at
+WHERE NOT post_states.read
+`
+
+type MarkAllPostsReadParams struct {
+	UserID uuid.UUID
+	ReadAt sql.NullTime
+}
+
+func (q *Queries) MarkAllPostsRead(ctx context.Context, arg MarkAllPostsReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markAllPostsRead, arg.UserID, arg.ReadAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markFeedPostsRead = `-- name: MarkFeedPostsRead :execrows
+INSERT INTO post_states (user_id, post_id, read, read_at)
+SELECT $1, posts.id, TRUE, $2 FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = $1
+)
+AND ($3::bigint = 0 OR feeds.serial_id = $3::bigint)
+AND COALESCE(posts.published_at, posts.created_at) < $4::timestamp
+ON CONFLICT (user_id, post_id) DO UPDATE SET read = TRUE, read_at = EXCLUDED.read_at
+WHERE NOT post_states.read
+`
+
+type MarkFeedPostsReadParams struct {
+	UserID       uuid.UUID
+	ReadAt       sql.NullTime
+	FeedSerialID int64
+	Before       time.Time
+}
+
+func (q *Queries) MarkFeedPostsRead(ctx context.Context, arg MarkFeedPostsReadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markFeedPostsRead,
+		arg.UserID,
+		arg.ReadAt,
+		arg.FeedSerialID,
+		arg.Before,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const markPostRead = `-- name: MarkPostRead :exec
+INSERT INTO post_states (user_id, post_id, read, read_at)
+VALUES ($1, $2, TRUE, $3)
+ON CONFLICT (user_id, post_id) DO UPDATE SET read = TRUE, read_at = EXCLUDED.read_at
+`
+
+type MarkPostReadParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt sql.NullTime
+}
+
+func (q *Queries) MarkPostRead(ctx context.Context, arg MarkPostReadParams) error {
+	_, err := q.db.ExecContext(ctx, markPostRead, arg.UserID, arg.PostID, arg.ReadAt)
+	return err
+}
+
+const markPostUnread = `-- name: MarkPostUnread :execrows
+UPDATE post_states SET read = FALSE, read_at = NULL
+WHERE user_id = $1 AND post_id = $2 AND read
+`
+
+type MarkPostUnreadParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+func (q *Queries) MarkPostUnread(ctx context.Context, arg MarkPostUnreadParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markPostUnread, arg.UserID, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const savePost = `-- name: SavePost :exec
+INSERT INTO saved_posts (user_id, post_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING
+`
+
+type SavePostParams struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+}
+
+func (q *Queries) SavePost(ctx context.Context, arg SavePostParams) error {
+	_, err := q.db.ExecContext(ctx, savePost, arg.UserID, arg.PostID, arg.CreatedAt)
+	return err
+}
+
+const searchPosts = `-- name: SearchPosts :many
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.serial_id, posts.guid, ts_rank(to_tsvector('english', COALESCE(posts.title, '') || ' ' || COALESCE(posts.description, '')), websearch_to_tsquery('english', $1)) AS rank
+FROM posts
+WHERE to_tsvector('english', COALESCE(posts.title, '') || ' ' || COALESCE(posts.description, '')) @@ websearch_to_tsquery('english', $1)
+AND ($2::boolean OR posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = $3
+))
+ORDER BY rank DESC, COALESCE(posts.published_at, posts.created_at) DESC
+LIMIT $4
+`
+
+type SearchPostsParams struct {
+	Query    string
+	AllFeeds bool
+	UserID   uuid.UUID
+	Limit    int32
+}
+
+type SearchPostsRow struct {
+	Post Post
+	Rank float32
+}
+
+func (q *Queries) SearchPosts(ctx context.Context, arg SearchPostsParams) ([]SearchPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPosts,
+		arg.Query,
+		arg.AllFeeds,
+		arg.UserID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsRow
+	for rows.Next() {
+		var i SearchPostsRow
+		if err := rows.Scan(
+			&i.Post.ID,
+			&i.Post.CreatedAt,
+			&i.Post.UpdatedAt,
+			&i.Post.Title,
+			&i.Post.Url,
+			&i.Post.Description,
+			&i.Post.PublishedAt,
+			&i.Post.FeedID,
+			&i.Post.SerialID,
+			&i.Post.Guid,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
@@ -106,3 +675,98 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	}
 	return items, nil
 }
+
+const unsavePost = `-- name: UnsavePost :execrows
+DELETE FROM saved_posts WHERE user_id = $1 AND post_id = $2
+`
+
+type UnsavePostParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+func (q *Queries) UnsavePost(ctx context.Context, arg UnsavePostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, unsavePost, arg.UserID, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const upsertPost = `-- name: UpsertPost :one
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, guid)
+VALUES ($1,
+     $2,
+     $3,
+     $4,
+     $5,
+     $6,
+     $7,
+     $8,
+     $9
+     )
+ON CONFLICT (feed_id, guid) DO UPDATE SET
+     title = EXCLUDED.title,
+     url = EXCLUDED.url,
+     description = EXCLUDED.description,
+     published_at = COALESCE(EXCLUDED.published_at, posts.published_at),
+     updated_at = EXCLUDED.updated_at
+WHERE (posts.title, posts.url, posts.description, posts.published_at)
+     IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.url, EXCLUDED.description, COALESCE(EXCLUDED.published_at, posts.published_at))
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, serial_id, guid, (xmax = 0)::boolean AS inserted
+`
+
+type UpsertPostParams struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       sql.NullString
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedID      uuid.UUID
+	Guid        string
+}
+
+type UpsertPostRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       sql.NullString
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedID      uuid.UUID
+	SerialID    int64
+	Guid        string
+	Inserted    bool
+}
+
+func (q *Queries) UpsertPost(ctx context.Context, arg UpsertPostParams) (UpsertPostRow, error) {
+	row := q.db.QueryRowContext(ctx, upsertPost,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+		arg.FeedID,
+		arg.Guid,
+	)
+	var i UpsertPostRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.SerialID,
+		&i.Guid,
+		&i.Inserted,
+	)
+	return i, err
+}
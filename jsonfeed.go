@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+)
+
+// jsonFeedVersionPrefix starts the version of every JSON Feed, e.g. https://jsonfeed.org/version/1.1
+const jsonFeedVersionPrefix = "https://jsonfeed.org/version/"
+
+type jsonFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Icon        string         `json:"icon"`
+	Favicon     string         `json:"favicon"`
+	Language    string         `json:"language"`
+	Items       []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	ExternalURL   string `json:"external_url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html"`
+	ContentText   string `json:"content_text"`
+	Summary       string `json:"summary"`
+	DatePublished string `json:"date_published"`
+	DateModified  string `json:"date_modified"`
+}
+
+// isJSONFeed checks the content type first and falls back to sniffing the body, parseJSONFeed checks the version
+func isJSONFeed(contentType string, body []byte) bool {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if mediaType == "application/feed+json" || mediaType == "application/json" {
+			return true
+		}
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
+func parseJSONFeed(body []byte) (*RSSFeed, error) {
+	var data jsonFeed
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(data.Version, jsonFeedVersionPrefix) {
+		return nil, fmt.Errorf("not a JSON feed, version: %q", data.Version)
+	}
+
+	var feed RSSFeed
+	feed.Channel.Title = data.Title
+	feed.Channel.Link = data.HomePageURL
+	feed.Channel.Description = data.Description
+	feed.Channel.Language = data.Language
+	feed.Channel.Image = data.Icon
+	if feed.Channel.Image == "" {
+		feed.Channel.Image = data.Favicon
+	}
+
+	for _, item := range data.Items {
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+
+		description := item.Summary
+		if description == "" {
+			description = item.ContentHTML
+		}
+		if description == "" {
+			description = item.ContentText
+		}
+
+		pubDate := item.DatePublished
+		if pubDate == "" {
+			pubDate = item.DateModified
+		}
+
+		feed.Channel.Item = append(feed.Channel.Item, RSSItem{
+			GUID:        strings.TrimSpace(item.ID),
+			Title:       strings.TrimSpace(item.Title),
+			Link:        link,
+			Description: strings.TrimSpace(description),
+			PubDate:     strings.TrimSpace(pubDate),
+		})
+	}
+
+	return &feed, nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+const jsonFeedFixture = `{
+  "version": "https://jsonfeed.org/version/1.1",
+  "title": "Example JSON",
+  "home_page_url": "https://example.com/",
+  "description": "A test feed",
+  "favicon": "https://example.com/favicon.ico",
+  "language": "en",
+  "items": [
+    {
+      "id": "1",
+      "url": "https://example.com/1",
+      "external_url": "https://elsewhere.com/1",
+      "title": "Summary first",
+      "summary": "The summary",
+      "content_html": "<p>The html</p>",
+      "date_published": "2024-01-02T03:04:05Z",
+      "date_modified": "2024-02-02T03:04:05Z"
+    },
+    {
+      "id": " 2 ",
+      "external_url": "https://elsewhere.com/2",
+      "title": "Html content",
+      "content_html": "<p>The html</p>",
+      "content_text": "The text",
+      "date_modified": "2024-02-03T00:00:00Z"
+    },
+    {
+      "id": "3",
+      "url": "https://example.com/3",
+      "content_text": "The text"
+    }
+  ]
+}`
+
+func TestParseJSONFeed(t *testing.T) {
+	feed, err := parseJSONFeed([]byte(jsonFeedFixture))
+	if err != nil {
+		t.Fatalf("parseJSONFeed() error: %v", err)
+	}
+
+	if feed.Channel.Title != "Example JSON" || feed.Channel.Link != "https://example.com/" || feed.Channel.Description != "A test feed" {
+		t.Errorf("channel = %q, %q, %q", feed.Channel.Title, feed.Channel.Link, feed.Channel.Description)
+	}
+	if feed.Channel.Image != "https://example.com/favicon.ico" {
+		t.Errorf("image = %q, want the favicon when there is no icon", feed.Channel.Image)
+	}
+
+	want := []RSSItem{
+		{GUID: "1", Title: "Summary first", Link: "https://example.com/1", Description: "The summary", PubDate: "2024-01-02T03:04:05Z"},
+		{GUID: "2", Title: "Html content", Link: "https://elsewhere.com/2", Description: "<p>The html</p>", PubDate: "2024-02-03T00:00:00Z"},
+		{GUID: "3", Link: "https://example.com/3", Description: "The text"},
+	}
+	if !reflect.DeepEqual(feed.Channel.Item, want) {
+		t.Errorf("items =\n%+v\nwant\n%+v", feed.Channel.Item, want)
+	}
+}
+
+func TestParseJSONFeedVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"version 1", `{"version": "https://jsonfeed.org/version/1", "items": []}`, false},
+		{"version 1.1", `{"version": "https://jsonfeed.org/version/1.1", "items": []}`, false},
+		{"no version", `{"title": "x", "items": []}`, true},
+		{"other version url", `{"version": "https://example.com/version/1"}`, true},
+		{"not an object", `[1, 2, 3]`, true},
+	}
+
+	for _, test := range tests {
+		_, err := parseJSONFeed([]byte(test.body))
+		if (err != nil) != test.wantErr {
+			t.Errorf("%v: parseJSONFeed() error = %v, want error %v", test.name, err, test.wantErr)
+		}
+	}
+}
+
+func TestIsJSONFeed(t *testing.T) {
+	tests := []struct {
+		contentType string
+		body        string
+		want        bool
+	}{
+		{"application/feed+json", "", true},
+		{"application/json; charset=utf-8", "", true},
+		{"text/plain", "\n  {}", true},
+		{"application/rss+xml", "<rss/>", false},
+		{"", "", false},
+	}
+
+	for _, test := range tests {
+		if got := isJSONFeed(test.contentType, []byte(test.body)); got != test.want {
+			t.Errorf("isJSONFeed(%q, %q) = %v, want %v", test.contentType, test.body, got, test.want)
+		}
+	}
+}
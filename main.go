@@ -2,7 +2,10 @@ package main
 
 import (
 	"database/sql"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 
 	_ "github.com/lib/pq"
@@ -22,31 +25,210 @@ func main() {
 	}
 	dbQueries := database.New(db)
 
-	states := &state{dbQueries, &currConfig}
-	commands := &commands{make(map[string]func(*state, command) error)}
+	// global flags come before the command name
+	globalFlags := flag.NewFlagSet("gator", flag.ContinueOnError)
+	globalFlags.SetOutput(io.Discard)
+	output := globalFlags.String("output", "", "output format of listings: json, csv or table")
 
-	commands.register("login", handlerLogin)
-	commands.register("register", handlerRegister)
-	commands.register("reset", handlerReset)
-	commands.register("users", handlerUsers)
-	commands.register("agg", handlerAgg)
-	commands.register("addfeed", middlewareLoggedIn(handlerAddFeed))
-	commands.register("feeds", handlerFeeds)
-	commands.register("follow", middlewareLoggedIn(handlerFollow))
-	commands.register("following", middlewareLoggedIn(handlerFollowingList))
-	commands.register("unfollow", middlewareLoggedIn(handlerUnfollow))
-	commands.register("browse", middlewareLoggedIn(handlerBrowse))
+	helpRequested := false
+	if err := globalFlags.Parse(os.Args[1:]); errors.Is(err, flag.ErrHelp) {
+		helpRequested = true
+	} else if err != nil {
+		fmt.Println("erorr:", err)
+		os.Exit(1)
+	}
+
+	states := &state{db: dbQueries, config: &currConfig, output: *output}
+	commands := &commands{make(map[string]commandInfo)}
 
-	arguments := os.Args
+	commands.register("help", commandInfo{
+		usage:       "help [command]",
+		description: "List the commands, or show the usage and flags of one command",
+		handler:     commands.handlerHelp,
+	})
+	commands.register("login", commandInfo{
+		usage:       "login <username>",
+		description: "Log in as a user",
+		handler:     handlerLogin,
+	})
+	commands.register("register", commandInfo{
+		usage:       "register <username>",
+		description: "Register a new user and log in as them",
+		handler:     handlerRegister,
+	})
+	commands.register("reset", commandInfo{
+		usage:       "reset",
+		description: "Delete every user along with their feeds and posts",
+		handler:     handlerReset,
+	})
+	commands.register("users", commandInfo{
+		usage:       "users [--output format]",
+		description: "List all users",
+		flags:       outputFlag,
+		handler:     handlerUsers,
+	})
+	commands.register("agg", commandInfo{
+		usage:       "agg [flags] <time between requests>",
+		description: "Run the feed aggregator, fetching stale feeds every interval, examples: 1s, 1m, 1h, 1h10m10s",
+		flags: func(f *flag.FlagSet) {
+			f.Int("concurrency", 1, "number of feeds scraped in parallel")
+			f.Bool("once", false, "fetch every stale feed once and exit, the time argument is optional and sets how old a fetch has to be")
+			f.Int("max-failures", 10, "consecutive fetch failures before a feed is disabled")
+		},
+		handler: handlerAgg,
+	})
+	commands.register("addfeed", commandInfo{
+		usage:       "addfeed [--no-discover] [name] <url>",
+		description: "Add a new feed and follow it, named after its title unless a name is given, a web page url is replaced by the feed it links to (requires login)",
+		flags: func(f *flag.FlagSet) {
+			f.Bool("no-discover", false, "add the url as given without checking it or looking for a feed on the page")
+		},
+		handler: middlewareLoggedIn(handlerAddFeed),
+	})
+	commands.register("feeds", commandInfo{
+		usage:       "feeds [--output format]",
+		description: "List all feeds",
+		flags:       outputFlag,
+		handler:     handlerFeeds,
+	})
+	commands.register("feedstatus", commandInfo{
+		usage:       "feedstatus [flags]",
+		description: "Show fetch health for every feed",
+		flags: func(f *flag.FlagSet) {
+			f.Bool("failing", false, "only show feeds whose last fetch failed or that are disabled")
+			f.Bool("never-fetched", false, "only show feeds that have never been fetched")
+		},
+		handler: handlerFeedStatus,
+	})
+	commands.register("enable", commandInfo{
+		usage:       "enable <url>",
+		description: "Fetch a disabled or failing feed again on the next agg pass",
+		handler:     handlerEnable,
+	})
+	commands.register("follow", commandInfo{
+		usage:       "follow <url>",
+		description: "Follow a feed (requires login)",
+		handler:     middlewareLoggedIn(handlerFollow),
+	})
+	commands.register("following", commandInfo{
+		usage:       "following [--output format]",
+		description: "Show feeds you are following (requires login)",
+		flags:       outputFlag,
+		handler:     middlewareLoggedIn(handlerFollowingList),
+	})
+	commands.register("unfollow", commandInfo{
+		usage:       "unfollow <url>",
+		description: "Stop following a feed (requires login)",
+		handler:     middlewareLoggedIn(handlerUnfollow),
+	})
+	commands.register("browse", commandInfo{
+		usage:       "browse [flags] [limit]",
+		description: "Show the newest unread posts of the feeds you follow, limit defaults to 2 (requires login)",
+		flags: func(f *flag.FlagSet) {
+			f.Bool("all", false, "include posts that have been read")
+			f.String("feed", "", "only show posts of the feed with this url or name")
+			f.String("since", "", "only show posts published at or after this date or duration ago, examples: 2024-01-31, 48h")
+			f.String("until", "", "only show posts published before this date or duration ago")
+			f.Int("offset", 0, "number of posts to skip")
+			f.Int("page", 0, "page of posts to show, pages are limit posts long, overrides offset")
+			f.String("order", "newest", "newest or oldest first")
+			outputFlag(f)
+		},
+		handler: middlewareLoggedIn(handlerBrowse),
+	})
+	commands.register("read", commandInfo{
+		usage:       "read [flags] <post-id>",
+		description: "Mark a post as read (requires login)",
+		flags: func(f *flag.FlagSet) {
+			f.Bool("all", false, "mark every post of the followed feeds as read")
+		},
+		handler: middlewareLoggedIn(handlerRead),
+	})
+	commands.register("unread", commandInfo{
+		usage:       "unread <post-id>",
+		description: "Mark a post as unread (requires login)",
+		handler:     middlewareLoggedIn(handlerUnread),
+	})
+	commands.register("save", commandInfo{
+		usage:       "save <post-id>",
+		description: "Save a post (requires login)",
+		handler:     middlewareLoggedIn(handlerSave),
+	})
+	commands.register("unsave", commandInfo{
+		usage:       "unsave <post-id>",
+		description: "Remove a post from your saved posts (requires login)",
+		handler:     middlewareLoggedIn(handlerUnsave),
+	})
+	commands.register("saved", commandInfo{
+		usage:       "saved [--output format]",
+		description: "Show your saved posts (requires login)",
+		flags:       outputFlag,
+		handler:     middlewareLoggedIn(handlerSaved),
+	})
+	commands.register("search", commandInfo{
+		usage:       "search [flags] <query>",
+		description: `Full-text search the posts of the feeds you follow, wrap phrases in double quotes (requires login)`,
+		flags: func(f *flag.FlagSet) {
+			f.Bool("all", false, "search the posts of every feed, not only followed ones")
+			f.Int("limit", 10, "maximum number of results")
+			outputFlag(f)
+		},
+		dashArguments: true,
+		handler:       middlewareLoggedIn(handlerSearch),
+	})
+	commands.register("import", commandInfo{
+		usage:       "import opml <file>",
+		description: "Import and follow the feeds of an OPML file (requires login)",
+		handler:     middlewareLoggedIn(handlerImport),
+	})
+	commands.register("export", commandInfo{
+		usage:       "export opml [file]",
+		description: "Export the feeds you follow as OPML to a file or stdout (requires login)",
+		handler:     middlewareLoggedIn(handlerExport),
+	})
+	commands.register("merged", commandInfo{
+		usage:       "merged [--format rss|atom] [--limit n] [--self-url url] [file]",
+		description: "Write the posts of every feed you follow as one RSS or Atom feed (requires login)",
+		flags: func(f *flag.FlagSet) {
+			f.String("format", "atom", "feed format, rss or atom")
+			f.Int("limit", 50, "maximum number of posts")
+			f.String("self-url", "", "url the feed will be published at, required for rss")
+		},
+		handler: middlewareLoggedIn(handlerMerged),
+	})
+	commands.register("token", commandInfo{
+		usage:       "token create [--feed] [name] | token revoke <id|name> | token list",
+		description: "Manage the API tokens used to authenticate to serve (requires login)",
+		flags: func(f *flag.FlagSet) {
+			f.Bool("feed", false, "create a token that can only load the merged feed, for feed reader urls")
+		},
+		handler: middlewareLoggedIn(handlerToken),
+	})
+	commands.register("serve", commandInfo{
+		usage:       "serve [--addr host:port]",
+		description: "Serve users, feeds, follows and posts over an HTTP JSON API",
+		flags: func(f *flag.FlagSet) {
+			f.String("addr", ":8080", "address to listen on")
+		},
+		handler: handlerServe,
+	})
+
+	arguments := globalFlags.Args()
+
+	if helpRequested {
+		arguments = []string{"help"}
+	}
 
-	if len(arguments) < 2 {
+	if len(arguments) < 1 {
 		fmt.Println("invalid command, not enough arguments")
+		fmt.Println()
+		commands.printHelp()
 		os.Exit(1)
 	}
 
 	command := command{
-		name:      arguments[1],
-		arguments: arguments[2:],
+		name:      arguments[0],
+		arguments: arguments[1:],
 	}
 
 	if err := commands.run(states, command); err != nil {
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saifullah605/Gator/internal/database"
+)
+
+var mergedFormats = []string{"rss", "atom"}
+
+type mergedRSS struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	Channel struct {
+		Title         string          `xml:"title"`
+		Link          string          `xml:"link"`
+		Description   string          `xml:"description"`
+		LastBuildDate string          `xml:"lastBuildDate"`
+		Generator     string          `xml:"generator"`
+		Items         []mergedRSSItem `xml:"item"`
+	} `xml:"channel"`
+}
+
+type mergedRSSItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description,omitempty"`
+	PubDate     string `xml:"pubDate"`
+	GUID        struct {
+		IsPermaLink bool   `xml:"isPermaLink,attr"`
+		Value       string `xml:",chardata"`
+	} `xml:"guid"`
+	Source struct {
+		URL  string `xml:"url,attr"`
+		Name string `xml:",chardata"`
+	} `xml:"source"`
+}
+
+type mergedAtom struct {
+	XMLName   xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	ID        string   `xml:"id"`
+	Title     string   `xml:"title"`
+	Updated   string   `xml:"updated"`
+	Generator string   `xml:"generator"`
+	Author    struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Links   []atomLink        `xml:"link"`
+	Entries []mergedAtomEntry `xml:"entry"`
+}
+
+type mergedAtomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published,omitempty"`
+	Updated   string     `xml:"updated"`
+	Summary   *atomText  `xml:"summary,omitempty"`
+	Source    struct {
+		ID    string     `xml:"id"`
+		Title string     `xml:"title"`
+		Links []atomLink `xml:"link"`
+	} `xml:"source"`
+}
+
+func validMergedFormat(format string) bool {
+	for _, valid := range mergedFormats {
+		if format == valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// loadMergedPosts returns the newest posts of every feed the user follows, read or not, with their feeds by id
+func loadMergedPosts(ctx context.Context, s *state, user database.User, limit int32) ([]database.Post, map[uuid.UUID]database.Feed, error) {
+	posts, err := s.db.GetPostsForUser(ctx, database.GetPostsForUserParams{
+		UserID:      user.ID,
+		IncludeRead: true,
+		Limit:       limit,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot load posts, error: %v", err)
+	}
+
+	feeds, err := s.db.GetFollowedFeedsForUser(ctx, user.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot get followed feeds, error: %v", err)
+	}
+
+	feedsByID := make(map[uuid.UUID]database.Feed, len(feeds))
+	for _, feed := range feeds {
+		feedsByID[feed.ID] = feed
+	}
+
+	return posts, feedsByID, nil
+}
+
+// postDate is the publish date of a post, or when it was collected for posts without one
+func postDate(post database.Post) time.Time {
+	if post.PublishedAt.Valid {
+		return post.PublishedAt.Time
+	}
+	return post.CreatedAt
+}
+
+// writeMergedFeed writes posts as one RSS 2.0 or Atom document, selfURL is where the document is served from,
+// RSS 2.0 requires a channel link so it can only be empty for atom
+func writeMergedFeed(w io.Writer, format string, user database.User, selfURL string, posts []database.Post, feeds map[uuid.UUID]database.Feed) error {
+	title := "Gator feeds of " + user.Name
+	updated := time.Now().UTC()
+	if len(posts) != 0 {
+		updated = postDate(posts[0])
+	}
+
+	var document any
+
+	switch format {
+	case "rss":
+		if selfURL == "" {
+			return fmt.Errorf("rss feeds need the url they are published at")
+		}
+
+		var rss mergedRSS
+		rss.Version = "2.0"
+		rss.Channel.Title = title
+		rss.Channel.Link = selfURL
+		rss.Channel.Description = "Posts of every feed " + user.Name + " follows on Gator"
+		rss.Channel.LastBuildDate = updated.Format(time.RFC1123Z)
+		rss.Channel.Generator = "gator"
+
+		for _, post := range posts {
+			item := mergedRSSItem{
+				Title:       post.Title.String,
+				Link:        post.Url,
+				Description: post.Description.String,
+				PubDate:     postDate(post).Format(time.RFC1123Z),
+			}
+			// links are not unique across feeds, the post id is
+			item.GUID.IsPermaLink = false
+			item.GUID.Value = "urn:uuid:" + post.ID.String()
+			item.Source.URL = feeds[post.FeedID].Url
+			item.Source.Name = feeds[post.FeedID].Name
+
+			rss.Channel.Items = append(rss.Channel.Items, item)
+		}
+
+		document = rss
+	case "atom":
+		var atom mergedAtom
+		atom.ID = "urn:uuid:" + user.ID.String()
+		atom.Title = title
+		atom.Updated = updated.Format(time.RFC3339)
+		atom.Generator = "gator"
+		atom.Author.Name = user.Name
+		if selfURL != "" {
+			atom.Links = []atomLink{{Href: selfURL, Rel: "self"}}
+		}
+
+		for _, post := range posts {
+			entry := mergedAtomEntry{
+				ID:      "urn:uuid:" + post.ID.String(),
+				Title:   post.Title.String,
+				Links:   []atomLink{{Href: post.Url, Rel: "alternate"}},
+				Updated: postDate(post).Format(time.RFC3339),
+			}
+			if post.PublishedAt.Valid {
+				entry.Published = post.PublishedAt.Time.Format(time.RFC3339)
+			}
+			if post.Description.String != "" {
+				entry.Summary = &atomText{Type: "html", Text: post.Description.String}
+			}
+
+			feed := feeds[post.FeedID]
+			entry.Source.ID = feed.Url
+			entry.Source.Title = feed.Name
+			entry.Source.Links = []atomLink{{Href: feed.Url, Rel: "self"}}
+			if feed.SiteUrl.Valid {
+				entry.Source.Links = append(entry.Source.Links, atomLink{Href: feed.SiteUrl.String, Rel: "alternate"})
+			}
+
+			atom.Entries = append(atom.Entries, entry)
+		}
+
+		document = atom
+	default:
+		return fmt.Errorf("unknown feed format %v, use rss or atom", format)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func handlerMerged(s *state, cmd command, user database.User) error {
+	format := cmd.stringFlag("format")
+	if !validMergedFormat(format) {
+		return fmt.Errorf("unknown feed format %v, use rss or atom", format)
+	}
+
+	if format == "rss" && cmd.stringFlag("self-url") == "" {
+		return fmt.Errorf("rss feeds need a channel link, set --self-url")
+	}
+
+	limit := cmd.intFlag("limit")
+	if limit < 1 {
+		return fmt.Errorf("limit has to be a positive number")
+	}
+
+	posts, feeds, err := loadMergedPosts(context.Background(), s, user, int32(limit))
+	if err != nil {
+		return err
+	}
+
+	output := os.Stdout
+	if len(cmd.arguments) != 0 {
+		file, err := os.Create(cmd.arguments[0])
+		if err != nil {
+			return fmt.Errorf("cannot create feed file, error: %v", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	if err := writeMergedFeed(output, format, user, cmd.stringFlag("self-url"), posts, feeds); err != nil {
+		return fmt.Errorf("cannot write feed, error: %v", err)
+	}
+
+	if output != os.Stdout {
+		fmt.Println("wrote", len(posts), "posts to", cmd.arguments[0])
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/saifullah605/Gator/internal/database"
+)
+
+type opmlDocument struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    struct {
+		Title       string `xml:"title"`
+		DateCreated string `xml:"dateCreated,omitempty"`
+	} `xml:"head"`
+	Body struct {
+		Outlines []opmlOutline `xml:"outline"`
+	} `xml:"body"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+func (o opmlOutline) name() string {
+	if title := strings.TrimSpace(o.Title); title != "" {
+		return title
+	}
+	if text := strings.TrimSpace(o.Text); text != "" {
+		return text
+	}
+	return strings.TrimSpace(o.XMLURL)
+}
+
+// subscriptions flattens category outlines, leaf outlines without an xmlUrl are still returned so they can be reported
+func (o opmlOutline) subscriptions() []opmlOutline {
+	if len(o.Outlines) == 0 {
+		return []opmlOutline{o}
+	}
+
+	var outlines []opmlOutline
+	if o.XMLURL != "" {
+		outlines = append(outlines, o)
+	}
+	for _, child := range o.Outlines {
+		outlines = append(outlines, child.subscriptions()...)
+	}
+
+	return outlines
+}
+
+func readOPML(path string) ([]opmlOutline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var document opmlDocument
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	var outlines []opmlOutline
+	for _, outline := range document.Body.Outlines {
+		outlines = append(outlines, outline.subscriptions()...)
+	}
+
+	return outlines, nil
+}
+
+// writeOPML writes an OPML 2.0 document with one outline per feed
+func writeOPML(w io.Writer, title string, feeds []database.Feed) error {
+	var document opmlDocument
+	document.Version = "2.0"
+	document.Head.Title = title
+	document.Head.DateCreated = time.Now().UTC().Format(time.RFC1123Z)
+
+	for _, feed := range feeds {
+		document.Body.Outlines = append(document.Body.Outlines, opmlOutline{
+			Text:    feed.Name,
+			Title:   feed.Name,
+			Type:    "rss",
+			XMLURL:  feed.Url,
+			HTMLURL: feed.SiteUrl.String,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func validFeedURL(feedURL string) bool {
+	parsed, err := url.Parse(feedURL)
+	if err != nil {
+		return false
+	}
+
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saifullah605/Gator/internal/database"
+)
+
+var outputFormats = []string{"json", "csv", "table"}
+
+// outputFlag registers --output for the commands that list records, it overrides the global flag
+func outputFlag(f *flag.FlagSet) {
+	f.String("output", "", "output format of listings: json, csv or table, human readable text by default")
+}
+
+func validOutputFormat(format string) bool {
+	if format == "" {
+		return true
+	}
+
+	for _, valid := range outputFormats {
+		if format == valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// record is a row of structured output, json uses the struct tags and csv/table use header and row
+type record interface {
+	header() []string
+	row() []string
+}
+
+func writeRecords[T record](format string, records []T) error {
+	var zero T
+
+	switch format {
+	case "json":
+		if records == nil {
+			records = []T{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case "csv":
+		writer := csv.NewWriter(os.Stdout)
+		if err := writer.Write(zero.header()); err != nil {
+			return err
+		}
+		for _, r := range records {
+			if err := writer.Write(r.row()); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	case "table":
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, strings.ToUpper(strings.Join(zero.header(), "\t")))
+		for _, r := range records {
+			fmt.Fprintln(writer, strings.Join(r.row(), "\t"))
+		}
+		return writer.Flush()
+	default:
+		return fmt.Errorf("unknown output format %v, use one of: %v", format, strings.Join(outputFormats, ", "))
+	}
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+func nullTimePointer(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+type userRecord struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Name      string    `json:"name"`
+	Current   bool      `json:"current"`
+}
+
+func newUserRecord(user database.User, currentUser string) userRecord {
+	return userRecord{
+		ID:        user.ID,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		Name:      user.Name,
+		Current:   user.Name == currentUser,
+	}
+}
+
+func (r userRecord) header() []string {
+	return []string{"id", "created_at", "updated_at", "name", "current"}
+}
+
+func (r userRecord) row() []string {
+	return []string{r.ID.String(), formatTime(r.CreatedAt), formatTime(r.UpdatedAt), r.Name, fmt.Sprint(r.Current)}
+}
+
+type feedRecord struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Name          string     `json:"name"`
+	Url           string     `json:"url"`
+	SiteUrl       string     `json:"site_url,omitempty"`
+	User          string     `json:"user"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
+
+func newFeedRecord(feed database.GetFeedsRow) feedRecord {
+	return feedRecord{
+		ID:            feed.ID,
+		CreatedAt:     feed.CreatedAt,
+		UpdatedAt:     feed.UpdatedAt,
+		Name:          feed.Name,
+		Url:           feed.Url,
+		SiteUrl:       feed.SiteUrl.String,
+		User:          feed.User,
+		LastFetchedAt: nullTimePointer(feed.LastFetchedAt),
+	}
+}
+
+func (r feedRecord) header() []string {
+	return []string{"id", "created_at", "updated_at", "name", "url", "site_url", "user", "last_fetched_at"}
+}
+
+func (r feedRecord) row() []string {
+	lastFetchedAt := ""
+	if r.LastFetchedAt != nil {
+		lastFetchedAt = formatTime(*r.LastFetchedAt)
+	}
+
+	return []string{r.ID.String(), formatTime(r.CreatedAt), formatTime(r.UpdatedAt), r.Name, r.Url, r.SiteUrl, r.User, lastFetchedAt}
+}
+
+type followRecord struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	FeedID    uuid.UUID `json:"feed_id"`
+	FeedName  string    `json:"feed_name"`
+	FeedUrl   string    `json:"feed_url"`
+	UserName  string    `json:"user_name"`
+}
+
+func newFollowRecord(follow database.GetFeedFollowsForUserRow) followRecord {
+	return followRecord{
+		ID:        follow.ID,
+		CreatedAt: follow.CreatedAt,
+		UpdatedAt: follow.UpdatedAt,
+		UserID:    follow.UserID,
+		FeedID:    follow.FeedID,
+		FeedName:  follow.FeedName,
+		FeedUrl:   follow.FeedUrl,
+		UserName:  follow.UserName,
+	}
+}
+
+func (r followRecord) header() []string {
+	return []string{"id", "created_at", "updated_at", "user_id", "feed_id", "feed_name", "feed_url", "user_name"}
+}
+
+func (r followRecord) row() []string {
+	return []string{r.ID.String(), formatTime(r.CreatedAt), formatTime(r.UpdatedAt), r.UserID.String(), r.FeedID.String(), r.FeedName, r.FeedUrl, r.UserName}
+}
+
+type postRecord struct {
+	ID          uuid.UUID  `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Title       string     `json:"title"`
+	Url         string     `json:"url"`
+	Description string     `json:"description"`
+	PublishedAt *time.Time `json:"published_at"`
+	FeedID      uuid.UUID  `json:"feed_id"`
+}
+
+func newPostRecord(post database.Post) postRecord {
+	return postRecord{
+		ID:          post.ID,
+		CreatedAt:   post.CreatedAt,
+		UpdatedAt:   post.UpdatedAt,
+		Title:       post.Title.String,
+		Url:         post.Url,
+		Description: post.Description.String,
+		PublishedAt: nullTimePointer(post.PublishedAt),
+		FeedID:      post.FeedID,
+	}
+}
+
+func (r postRecord) header() []string {
+	return []string{"id", "created_at", "updated_at", "title", "url", "description", "published_at", "feed_id"}
+}
+
+func (r postRecord) row() []string {
+	publishedAt := ""
+	if r.PublishedAt != nil {
+		publishedAt = formatTime(*r.PublishedAt)
+	}
+
+	return []string{r.ID.String(), formatTime(r.CreatedAt), formatTime(r.UpdatedAt), r.Title, r.Url, r.Description, publishedAt, r.FeedID.String()}
+}
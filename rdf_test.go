@@ -0,0 +1,53 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const rdfFixture = `<?xml version="1.0"?>
+<rdf:RDF
+  xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+  xmlns:dc="http://purl.org/dc/elements/1.1/"
+  xmlns="http://purl.org/rss/1.0/">
+  <channel rdf:about="https://example.com/rss">
+    <title> Example RDF </title>
+    <link>https://example.com/</link>
+    <description>A test feed</description>
+  </channel>
+  <item rdf:about="https://example.com/1">
+    <title>First</title>
+    <link>https://example.com/1</link>
+    <description>The first item</description>
+    <dc:date>2024-01-02T03:04:05+01:00</dc:date>
+  </item>
+  <item rdf:about="https://example.com/2">
+    <title>Second</title>
+    <link> https://example.com/2?ref=rss </link>
+  </item>
+</rdf:RDF>`
+
+func TestParseRDF(t *testing.T) {
+	feed, err := parseFeed([]byte(rdfFixture), "application/rdf+xml")
+	if err != nil {
+		t.Fatalf("parseFeed() error: %v", err)
+	}
+
+	if feed.Channel.Title != "Example RDF" || feed.Channel.Link != "https://example.com/" || feed.Channel.Description != "A test feed" {
+		t.Errorf("channel = %q, %q, %q", feed.Channel.Title, feed.Channel.Link, feed.Channel.Description)
+	}
+
+	want := []RSSItem{
+		{GUID: "https://example.com/1", Title: "First", Link: "https://example.com/1", Description: "The first item", PubDate: "2024-01-02T03:04:05+01:00"},
+		{GUID: "https://example.com/2", Title: "Second", Link: "https://example.com/2?ref=rss"},
+	}
+	if !reflect.DeepEqual(feed.Channel.Item, want) {
+		t.Errorf("items =\n%+v\nwant\n%+v", feed.Channel.Item, want)
+	}
+
+	published, err := itemPublishedAt(feed.Channel.Item[0])
+	if err != nil || !published.Equal(time.Date(2024, 1, 2, 2, 4, 5, 0, time.UTC)) {
+		t.Errorf("itemPublishedAt() = %v, %v, want the dc:date", published, err)
+	}
+}
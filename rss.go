@@ -1,50 +1,125 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/xml"
+	"errors"
+	"fmt"
 	"html"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type RSSFeed struct {
 	Channel struct {
-		Title       string    `xml:"title"`
-		Link        string    `xml:"link"`
-		Description string    `xml:"description"`
-		Item        []RSSItem `xml:"item"`
+		Title       string     `xml:"title"`
+		Link        string     `xml:"-"`
+		Links       []rssLink  `xml:"link"`
+		Description string     `xml:"description"`
+		Language    string     `xml:"language"`
+		Generator   string     `xml:"generator"`
+		Image       string     `xml:"-"`
+		Images      []rssImage `xml:"image"`
+		Item        []RSSItem  `xml:"item"`
 	} `xml:"channel"`
 }
 
+// rssLink also matches the atom:link elements many RSS feeds add next to the site link
+type rssLink struct {
+	XMLName xml.Name
+	Text    string `xml:",chardata"`
+}
+
+// rssImage is the channel <image>, or an itunes:image that keeps its url in href
+type rssImage struct {
+	XMLName xml.Name
+	URL     string `xml:"url"`
+	Href    string `xml:"href,attr"`
+}
+
 type RSSItem struct {
+	GUID        string `xml:"guid"`
 	Title       string `xml:"title"`
 	Link        string `xml:"link"`
 	Description string `xml:"description"`
 	PubDate     string `xml:"pubDate"`
+	DCDate      string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	AtomUpdated string `xml:"http://www.w3.org/2005/Atom updated"`
 }
 
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
+// itemGUID identifies an item within its feed, the link and then the title stand in when there is no guid
+func itemGUID(item RSSItem) string {
+	if guid := strings.TrimSpace(item.GUID); guid != "" {
+		return guid
+	}
+	if link := strings.TrimSpace(item.Link); link != "" {
+		return link
+	}
+	return strings.TrimSpace(item.Title)
+}
+
+var errNotModified = errors.New("feed not modified")
+
+// httpClient fetches feeds and pages, a server that never answers must not hang a worker
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// feedCache holds the validators used for conditional requests
+type feedCache struct {
+	etag         string
+	lastModified string
+}
+
+// feedResponse is what fetchFeed learned from the server, statusCode is 0 if no response was received
+type feedResponse struct {
+	statusCode int
+	cache      feedCache
+}
+
+// fetchFeed returns errNotModified when the server answers 304 to the cached validators
+func fetchFeed(ctx context.Context, feedURL string, cache feedCache) (*RSSFeed, feedResponse, error) {
 
 	//create new request
 	request, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
 	if err != nil {
-		return nil, err
+		return nil, feedResponse{cache: cache}, err
 	}
 	request.Header.Set("User-Agent", "gator")
+	if cache.etag != "" {
+		request.Header.Set("If-None-Match", cache.etag)
+	}
+	if cache.lastModified != "" {
+		request.Header.Set("If-Modified-Since", cache.lastModified)
+	}
 
 	//get responce
-	res, err := http.DefaultClient.Do(request)
+	res, err := httpClient.Do(request)
 	if err != nil {
-		return nil, err
+		return nil, feedResponse{cache: cache}, err
 	}
 
 	defer res.Body.Close()
 
+	response := feedResponse{statusCode: res.StatusCode, cache: cache}
+
+	if res.StatusCode == http.StatusNotModified {
+		return nil, response, errNotModified
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, response, fmt.Errorf("unexpected status: %v", res.Status)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, response, err
+	}
+
 	//decode response
-	var feed RSSFeed
-	decoder := xml.NewDecoder(res.Body)
-	if err := decoder.Decode(&feed); err != nil {
-		return nil, err
+	feed, err := parseFeed(body, res.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, response, err
 	}
 
 	feed.Channel.Title = html.UnescapeString(feed.Channel.Title)
@@ -57,6 +132,69 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 
 	}
 
-	return &feed, nil
+	response.cache = feedCache{
+		etag:         res.Header.Get("ETag"),
+		lastModified: res.Header.Get("Last-Modified"),
+	}
+
+	return feed, response, nil
 
 }
+
+// parseFeed decodes JSON feeds or xml feeds based on their root element
+func parseFeed(body []byte, contentType string) (*RSSFeed, error) {
+	if isJSONFeed(contentType, body) {
+		return parseJSONFeed(body)
+	}
+
+	root, err := rootElement(body)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case root.Local == "rss":
+		var feed RSSFeed
+		if err := xml.Unmarshal(body, &feed); err != nil {
+			return nil, err
+		}
+		for _, link := range feed.Channel.Links {
+			if link.XMLName.Space != atomNamespace && strings.TrimSpace(link.Text) != "" {
+				feed.Channel.Link = strings.TrimSpace(link.Text)
+				break
+			}
+		}
+		for _, image := range feed.Channel.Images {
+			if image.XMLName.Space == "" && strings.TrimSpace(image.URL) != "" {
+				feed.Channel.Image = strings.TrimSpace(image.URL)
+				break
+			}
+			if feed.Channel.Image == "" && strings.TrimSpace(image.Href) != "" {
+				feed.Channel.Image = strings.TrimSpace(image.Href)
+			}
+		}
+		feed.Channel.Language = strings.TrimSpace(feed.Channel.Language)
+		feed.Channel.Generator = strings.TrimSpace(feed.Channel.Generator)
+		return &feed, nil
+	case root.Local == "feed" && (root.Space == atomNamespace || root.Space == ""):
+		return parseAtom(body)
+	case root.Local == "RDF" && root.Space == rdfNamespace:
+		return parseRDF(body)
+	default:
+		return nil, fmt.Errorf("unsupported feed format, root element: %v", root.Local)
+	}
+}
+
+func rootElement(body []byte) (xml.Name, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return xml.Name{}, err
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name, nil
+		}
+	}
+}
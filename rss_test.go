@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseFeedFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		title       string
+		wantErr     bool
+	}{
+		{"rss 2.0", "application/rss+xml", `<?xml version="1.0"?><rss version="2.0"><channel><title>RSS</title></channel></rss>`, "RSS", false},
+		{"atom", "application/atom+xml", `<feed xmlns="http://www.w3.org/2005/Atom"><title>Atom</title></feed>`, "Atom", false},
+		{"atom without namespace", "text/xml", `<feed><title>Bare</title></feed>`, "Bare", false},
+		{"feed in another namespace", "text/xml", `<feed xmlns="http://example.com/ns"><title>Other</title></feed>`, "", true},
+		{"json feed", "application/feed+json", `{"version": "https://jsonfeed.org/version/1.1", "title": "JSON"}`, "JSON", false},
+		{"json feed served as text", "text/plain", `  {"version": "https://jsonfeed.org/version/1", "title": "Sniffed"}`, "Sniffed", false},
+		{"json without a version", "application/json", `{"title": "Plain JSON"}`, "", true},
+		{"rss 1.0", "application/rdf+xml", `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/"><channel><title>RDF</title></channel></rdf:RDF>`, "RDF", false},
+		{"RDF in another namespace", "text/xml", `<RDF xmlns="http://example.com/rdf"><channel><title>Other</title></channel></RDF>`, "", true},
+		{"html page", "text/html", `<html><head><title>Page</title></head></html>`, "", true},
+		{"not xml", "text/plain", `just text`, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			feed, err := parseFeed([]byte(test.body), test.contentType)
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("parseFeed() = %+v, want an error", feed.Channel)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFeed() error: %v", err)
+			}
+			if feed.Channel.Title != test.title {
+				t.Errorf("parseFeed() title = %q, want %q", feed.Channel.Title, test.title)
+			}
+		})
+	}
+}
@@ -0,0 +1,509 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/saifullah605/Gator/internal/database"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// apiServer exposes the same queries as the CLI over HTTP/JSON
+type apiServer struct {
+	state *state
+}
+
+type pageResponse[T any] struct {
+	Data   []T   `json:"data"`
+	Limit  int32 `json:"limit"`
+	Offset int32 `json:"offset"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func handlerServe(s *state, cmd command) error {
+	api := &apiServer{state: s}
+
+	server := &http.Server{
+		Addr:              cmd.stringFlag("addr"),
+		Handler:           api.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		fmt.Println("Serving the gator API on", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		return fmt.Errorf("server stopped, error: %v", err)
+	case <-ctx.Done():
+	}
+
+	// let in-flight requests finish before exiting
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("cannot shut down server, error: %v", err)
+	}
+
+	fmt.Println("server stopped")
+	return nil
+}
+
+func (api *apiServer) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /v1/users", api.authenticated(api.handleListUsers))
+	mux.HandleFunc("POST /v1/users", api.authenticated(api.handleCreateUser))
+	mux.HandleFunc("GET /v1/feeds", api.authenticated(api.handleListFeeds))
+	mux.HandleFunc("POST /v1/feeds", api.authenticated(api.handleCreateFeed))
+	mux.HandleFunc("GET /v1/follows", api.authenticated(api.handleListFollows))
+	mux.HandleFunc("POST /v1/follows", api.authenticated(api.handleCreateFollow))
+	mux.HandleFunc("DELETE /v1/follows", api.authenticated(api.handleDeleteFollow))
+	mux.HandleFunc("GET /v1/posts", api.authenticated(api.handleListPosts))
+	mux.HandleFunc("GET /v1/merged", api.authenticatedQueryToken(api.handleMergedFeed))
+	mux.HandleFunc("/fever", api.handleFever)
+	mux.HandleFunc("/fever/", api.handleFever)
+	api.greaderRoutes(mux)
+
+	return api.greaderStreamPaths(mux)
+}
+
+// authenticated is the HTTP version of middlewareLoggedIn, the user comes from the bearer token of the request
+func (api *apiServer) authenticated(handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	return api.authenticatedBy(bearerToken, api.state.db.GetUserByApiToken, handler)
+}
+
+// authenticatedQueryToken also takes a feed token from ?token=, for routes feed readers load since they can rarely send headers,
+// full tokens are only accepted in the header so they never end up in a reader's url
+func (api *apiServer) authenticatedQueryToken(handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	fromHeader := api.authenticated(handler)
+	fromQuery := api.authenticatedBy(func(r *http.Request) (string, bool) {
+		return r.URL.Query().Get("token"), r.URL.Query().Has("token")
+	}, api.state.db.GetUserByFeedToken, handler)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := bearerToken(r); ok {
+			fromHeader(w, r)
+			return
+		}
+		fromQuery(w, r)
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	return strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+// authenticatedBy looks up the user of the token tokenFrom finds in the request with lookup
+func (api *apiServer) authenticatedBy(tokenFrom func(r *http.Request) (string, bool), lookup func(ctx context.Context, tokenHash string) (database.User, error), handler func(w http.ResponseWriter, r *http.Request, user database.User)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := tokenFrom(r)
+		if !ok || token == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			respondError(w, http.StatusUnauthorized, "need an Authorization: Bearer <token> header, create a token with gator token create")
+			return
+		}
+
+		tokenHash := hashAPIToken(strings.TrimSpace(token))
+		user, err := lookup(r.Context(), tokenHash)
+		if err == sql.ErrNoRows {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			respondError(w, http.StatusUnauthorized, "invalid or revoked token")
+			return
+		} else if err != nil {
+			respondError(w, http.StatusInternalServerError, "cannot get user info")
+			return
+		}
+
+		// usage tracking is best effort, it should not fail the request
+		api.state.db.MarkApiTokenUsed(r.Context(), database.MarkApiTokenUsedParams{
+			LastUsedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+			TokenHash:  tokenHash,
+		})
+
+		handler(w, r, user)
+	}
+}
+
+func respondJSON(w http.ResponseWriter, status int, payload any) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+func respondError(w http.ResponseWriter, status int, message string) {
+	respondJSON(w, status, errorResponse{Error: message})
+}
+
+func decodeBody(r *http.Request, v any) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 1<<20))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+func isUniqueViolation(err error) bool {
+	pgErr, ok := err.(*pq.Error)
+	return ok && pgErr.Code == "23505"
+}
+
+// pagination reads limit and offset, page overrides offset like in browse
+func pagination(r *http.Request) (int32, int32, error) {
+	query := r.URL.Query()
+	limit, offset, page := defaultPageSize, 0, 0
+
+	for name, target := range map[string]*int{"limit": &limit, "offset": &offset, "page": &page} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return 0, 0, fmt.Errorf("%v has to be a non-negative number", name)
+		}
+		*target = parsed
+	}
+
+	if limit < 1 || limit > maxPageSize {
+		return 0, 0, fmt.Errorf("limit has to be between 1 and %v", maxPageSize)
+	}
+	if page > 0 {
+		offset = (page - 1) * limit
+	}
+
+	return int32(limit), int32(offset), nil
+}
+
+func (api *apiServer) handleListUsers(w http.ResponseWriter, r *http.Request, user database.User) {
+	limit, offset, err := pagination(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, err := api.state.db.ListUsers(r.Context(), database.ListUsersParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot get users")
+		return
+	}
+
+	records := make([]userRecord, 0, len(users))
+	for _, listed := range users {
+		records = append(records, newUserRecord(listed, user.Name))
+	}
+
+	respondJSON(w, http.StatusOK, pageResponse[userRecord]{Data: records, Limit: limit, Offset: offset})
+}
+
+func (api *apiServer) handleCreateUser(w http.ResponseWriter, r *http.Request, _ database.User) {
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := decodeBody(r, &body); err != nil || body.Name == "" {
+		respondError(w, http.StatusBadRequest, "need a json body with a name")
+		return
+	}
+
+	user, err := api.state.db.CreateUser(r.Context(), database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		Name:      body.Name,
+	})
+	if isUniqueViolation(err) {
+		respondError(w, http.StatusConflict, "name already used")
+		return
+	} else if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot create user")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, newUserRecord(user, ""))
+}
+
+func (api *apiServer) handleListFeeds(w http.ResponseWriter, r *http.Request, _ database.User) {
+	limit, offset, err := pagination(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	feeds, err := api.state.db.ListFeeds(r.Context(), database.ListFeedsParams{
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot get feeds")
+		return
+	}
+
+	records := make([]feedRecord, 0, len(feeds))
+	for _, feed := range feeds {
+		records = append(records, newFeedRecord(database.GetFeedsRow(feed)))
+	}
+
+	respondJSON(w, http.StatusOK, pageResponse[feedRecord]{Data: records, Limit: limit, Offset: offset})
+}
+
+func (api *apiServer) handleCreateFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	var body struct {
+		Name string `json:"name"`
+		Url  string `json:"url"`
+	}
+	if err := decodeBody(r, &body); err != nil || body.Name == "" || !validFeedURL(body.Url) {
+		respondError(w, http.StatusBadRequest, "need a json body with a name and an http(s) url")
+		return
+	}
+
+	feed, err := api.state.db.CreateFeed(r.Context(), database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		Name:      body.Name,
+		Url:       body.Url,
+		UserID:    user.ID,
+	})
+	if isUniqueViolation(err) {
+		respondError(w, http.StatusConflict, "feed already exist, follow it instead")
+		return
+	} else if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot add feed")
+		return
+	}
+
+	if _, err := api.state.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	}); err != nil {
+		respondError(w, http.StatusInternalServerError, "feed added, but cannot follow feed")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, newFeedRecord(database.GetFeedsRow{
+		ID:            feed.ID,
+		CreatedAt:     feed.CreatedAt,
+		UpdatedAt:     feed.UpdatedAt,
+		Name:          feed.Name,
+		Url:           feed.Url,
+		SiteUrl:       feed.SiteUrl,
+		LastFetchedAt: feed.LastFetchedAt,
+		User:          user.Name,
+	}))
+}
+
+func (api *apiServer) handleListFollows(w http.ResponseWriter, r *http.Request, user database.User) {
+	limit, offset, err := pagination(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	follows, err := api.state.db.ListFeedFollowsForUser(r.Context(), database.ListFeedFollowsForUserParams{
+		UserID: user.ID,
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot get followed feeds")
+		return
+	}
+
+	records := make([]followRecord, 0, len(follows))
+	for _, follow := range follows {
+		records = append(records, newFollowRecord(database.GetFeedFollowsForUserRow(follow)))
+	}
+
+	respondJSON(w, http.StatusOK, pageResponse[followRecord]{Data: records, Limit: limit, Offset: offset})
+}
+
+func (api *apiServer) handleCreateFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	var body struct {
+		Url string `json:"url"`
+	}
+	if err := decodeBody(r, &body); err != nil || body.Url == "" {
+		respondError(w, http.StatusBadRequest, "need a json body with the url of the feed")
+		return
+	}
+
+	feedId, err := api.state.db.GetFeedId(r.Context(), body.Url)
+	if err == sql.ErrNoRows {
+		respondError(w, http.StatusNotFound, "feed does not exist")
+		return
+	} else if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot look up feed")
+		return
+	}
+
+	follow, err := api.state.db.CreateFeedFollow(r.Context(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		FeedID:    feedId,
+	})
+	if isUniqueViolation(err) {
+		respondError(w, http.StatusConflict, "feed already followed")
+		return
+	} else if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot follow feed")
+		return
+	}
+
+	respondJSON(w, http.StatusCreated, followRecord{
+		ID:        follow.ID,
+		CreatedAt: follow.CreatedAt,
+		UpdatedAt: follow.UpdatedAt,
+		UserID:    follow.UserID,
+		FeedID:    follow.FeedID,
+		FeedName:  follow.FeedName,
+		FeedUrl:   body.Url,
+		UserName:  follow.UserName,
+	})
+}
+
+func (api *apiServer) handleDeleteFollow(w http.ResponseWriter, r *http.Request, user database.User) {
+	feedURL := r.URL.Query().Get("url")
+	if feedURL == "" {
+		respondError(w, http.StatusBadRequest, "need a url query parameter")
+		return
+	}
+
+	if _, err := api.state.db.Unfollow(r.Context(), database.UnfollowParams{
+		UserID: user.ID,
+		Url:    feedURL,
+	}); err == sql.ErrNoRows {
+		respondError(w, http.StatusNotFound, "user does not follow that feed")
+		return
+	} else if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot unfollow that feed")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleListPosts takes the browse flags as query parameters
+func (api *apiServer) handleListPosts(w http.ResponseWriter, r *http.Request, user database.User) {
+	limit, offset, err := pagination(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := r.URL.Query()
+
+	order := query.Get("order")
+	if order == "" {
+		order = "newest"
+	}
+	if order != "newest" && order != "oldest" {
+		respondError(w, http.StatusBadRequest, "order has to be newest or oldest")
+		return
+	}
+
+	since, err := parseBrowseTime(query.Get("since"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid since value")
+		return
+	}
+	until, err := parseBrowseTime(query.Get("until"))
+	if err != nil {
+		respondError(w, http.StatusBadRequest, "invalid until value")
+		return
+	}
+
+	posts, err := api.state.db.GetPostsForUserFiltered(r.Context(), database.GetPostsForUserFilteredParams{
+		UserID:      user.ID,
+		IncludeRead: query.Get("all") == "true",
+		Feed: sql.NullString{
+			String: query.Get("feed"),
+			Valid:  query.Get("feed") != "",
+		},
+		Since:       since,
+		Until:       until,
+		OldestFirst: order == "oldest",
+		Limit:       limit,
+		Offset:      offset,
+	})
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot load posts")
+		return
+	}
+
+	records := make([]postRecord, 0, len(posts))
+	for _, post := range posts {
+		records = append(records, newPostRecord(post))
+	}
+
+	respondJSON(w, http.StatusOK, pageResponse[postRecord]{Data: records, Limit: limit, Offset: offset})
+}
+
+// handleMergedFeed serves the posts of every followed feed as one rss or atom document
+func (api *apiServer) handleMergedFeed(w http.ResponseWriter, r *http.Request, user database.User) {
+	limit, _, err := pagination(r)
+	if err != nil {
+		respondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "atom"
+	}
+	if !validMergedFormat(format) {
+		respondError(w, http.StatusBadRequest, "format has to be rss or atom")
+		return
+	}
+
+	posts, feeds, err := loadMergedPosts(r.Context(), api.state, user, limit)
+	if err != nil {
+		respondError(w, http.StatusInternalServerError, "cannot load posts")
+		return
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	selfURL := scheme + "://" + r.Host + r.URL.Path + "?format=" + format
+
+	w.Header().Set("Content-Type", "application/"+format+"+xml; charset=utf-8")
+	writeMergedFeed(w, format, user, selfURL, posts, feeds)
+}
@@ -0,0 +1,37 @@
+-- name: CreateApiToken :one
+INSERT INTO api_tokens (id, created_at, user_id, name, token_hash, fever_key, scope)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+)
+RETURNING *;
+
+-- name: GetUserByApiToken :one
+SELECT users.* FROM users
+INNER JOIN api_tokens ON api_tokens.user_id = users.id
+WHERE api_tokens.token_hash = $1 AND api_tokens.scope = 'full';
+
+-- name: GetUserByFeedToken :one
+SELECT users.* FROM users
+INNER JOIN api_tokens ON api_tokens.user_id = users.id
+WHERE api_tokens.token_hash = $1 AND api_tokens.scope = 'feed';
+
+-- name: MarkApiTokenUsed :exec
+UPDATE api_tokens SET last_used_at = $1 WHERE token_hash = $2;
+
+-- name: GetApiTokensForUser :many
+SELECT * FROM api_tokens WHERE user_id = $1 ORDER BY created_at;
+
+-- name: RevokeApiToken :execrows
+DELETE FROM api_tokens
+WHERE user_id = sqlc.arg(user_id) AND (id::text = sqlc.arg(token)::text OR name = sqlc.arg(token)::text);
+
+-- name: GetUserByFeverKey :one
+SELECT users.* FROM users
+INNER JOIN api_tokens ON api_tokens.user_id = users.id
+WHERE api_tokens.fever_key = $1;
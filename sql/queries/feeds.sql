@@ -12,8 +12,8 @@ VALUES (
 RETURNING *;
 
 -- name: GetFeeds :many
-SELECT feeds.name, feeds.url, users.name as user FROM feeds
-INNER JOIN users ON feeds.user_id = users.id; 
+SELECT feeds.*, users.name as user FROM feeds
+INNER JOIN users ON feeds.user_id = users.id;
 
 -- name: GetFeedId :one
 SELECT id FROM feeds
@@ -41,7 +41,7 @@ WITH inserted_feed_follow AS (
 
 
 -- name: GetFeedFollowsForUser :many
-SELECT feed_follows.*, feeds.name as feed_name, users.name as user_name FROM feed_follows INNER JOIN feeds ON feed_follows.feed_id = feeds.id
+SELECT feed_follows.*, feeds.name as feed_name, feeds.url as feed_url, users.name as user_name FROM feed_follows INNER JOIN feeds ON feed_follows.feed_id = feeds.id
 INNER JOIN users ON feed_follows.user_id = users.id
 WHERE feed_follows.user_id = $1;
 
@@ -51,9 +51,70 @@ DELETE from feed_follows WHERE feed_follows.user_id = $1 AND feed_follows.feed_i
 )
 RETURNING *;
 
--- name: MarkFeedFetched :exec
-UPDATE feeds SET last_fetched_at = $1, updated_at = $1 WHERE id = $2;
+-- name: UpdateFeedCache :exec
+UPDATE feeds SET etag = $1, last_modified = $2 WHERE id = $3;
 
--- name: GetNextFeedToFetch :one
-SELECT * FROM feeds ORDER BY last_fetched_at ASC NULLS FIRST LIMIT 1;
+-- name: ClaimNextFeedToFetch :one
+UPDATE feeds SET last_fetched_at = sqlc.arg(fetched_at), updated_at = sqlc.arg(fetched_at)
+WHERE id = (
+     SELECT id FROM feeds
+     WHERE (last_fetched_at IS NULL OR last_fetched_at < sqlc.arg(stale_before))
+          AND NOT disabled
+          AND (next_fetch_at IS NULL OR next_fetch_at <= sqlc.arg(fetched_at))
+     ORDER BY last_fetched_at ASC NULLS FIRST
+     LIMIT 1
+     FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: RecordFeedFailure :exec
+UPDATE feeds SET fetch_failures = fetch_failures + 1, last_fetch_error = $2, next_fetch_at = $3, disabled = $4, last_http_status = $5
+WHERE id = $1;
+
+-- name: RecordFeedSuccess :exec
+UPDATE feeds SET fetch_failures = 0, last_fetch_error = NULL, next_fetch_at = NULL, last_success_at = $2, last_http_status = $3
+WHERE id = $1;
+
+-- name: EnableFeed :execrows
+UPDATE feeds SET disabled = FALSE, fetch_failures = 0, next_fetch_at = NULL, last_fetch_error = NULL
+WHERE url = $1;
 
+-- name: GetFeedStatuses :many
+SELECT feeds.id, feeds.name, feeds.url, feeds.last_fetched_at, feeds.last_success_at, feeds.last_http_status,
+     feeds.last_fetch_error, feeds.fetch_failures, feeds.disabled,
+     COUNT(posts.id) AS item_count,
+     COALESCE(
+          EXTRACT(EPOCH FROM (MAX(COALESCE(posts.published_at, posts.created_at)) - MIN(COALESCE(posts.published_at, posts.created_at))))
+          / NULLIF(COUNT(posts.id) - 1, 0),
+          0
+     )::float8 AS avg_seconds_between_posts
+FROM feeds
+LEFT JOIN posts ON posts.feed_id = feeds.id
+GROUP BY feeds.id
+ORDER BY feeds.name;
+
+-- name: UpdateFeedMetadata :exec
+UPDATE feeds SET site_url = COALESCE(sqlc.narg(site_url), site_url),
+     title = sqlc.narg(title),
+     description = sqlc.narg(description),
+     language = sqlc.narg(language),
+     image_url = sqlc.narg(image_url),
+     generator = sqlc.narg(generator)
+WHERE id = sqlc.arg(id);
+
+-- name: GetFollowedFeedsForUser :many
+SELECT feeds.* FROM feeds
+INNER JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name;
+
+-- name: ListFeeds :many
+SELECT feeds.*, users.name as user FROM feeds
+INNER JOIN users ON feeds.user_id = users.id
+ORDER BY feeds.name LIMIT $1 OFFSET $2;
+
+-- name: ListFeedFollowsForUser :many
+SELECT feed_follows.*, feeds.name as feed_name, feeds.url as feed_url, users.name as user_name FROM feed_follows INNER JOIN feeds ON feed_follows.feed_id = feeds.id
+INNER JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.user_id = $1
+ORDER BY feeds.name LIMIT $2 OFFSET $3;
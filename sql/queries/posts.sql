@@ -1,5 +1,13 @@
--- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
+-- name: AdoptPostGuid :execrows
+UPDATE posts SET guid = sqlc.arg(guid)
+WHERE feed_id = sqlc.arg(feed_id) AND url = sqlc.arg(url) AND guid = url AND guid <> sqlc.arg(guid)
+AND NOT EXISTS (
+    SELECT 1 FROM posts AS existing
+    WHERE existing.feed_id = sqlc.arg(feed_id) AND existing.guid = sqlc.arg(guid)
+);
+
+-- name: UpsertPost :one
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, guid)
 VALUES ($1,
      $2,
      $3,
@@ -7,12 +15,184 @@ VALUES ($1,
      $5,
      $6,
      $7,
-     $8
+     $8,
+     $9
      )
-RETURNING *;
+ON CONFLICT (feed_id, guid) DO UPDATE SET
+     title = EXCLUDED.title,
+     url = EXCLUDED.url,
+     description = EXCLUDED.description,
+     published_at = COALESCE(EXCLUDED.published_at, posts.published_at),
+     updated_at = EXCLUDED.updated_at
+WHERE (posts.title, posts.url, posts.description, posts.published_at)
+     IS DISTINCT FROM (EXCLUDED.title, EXCLUDED.url, EXCLUDED.description, COALESCE(EXCLUDED.published_at, posts.published_at))
+RETURNING *, (xmax = 0)::boolean AS inserted;
 
 -- name: GetPostsForUser :many
 SELECT posts.* FROM posts WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = sqlc.arg(user_id)
+) AND (sqlc.arg(include_read)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_states
+    WHERE post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id) AND post_states.read
+)) ORDER BY COALESCE(posts.published_at, posts.created_at) DESC LIMIT sqlc.arg('limit');
+
+-- name: GetPostsForUserFiltered :many
+SELECT posts.* FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = sqlc.arg(user_id)
+) AND (sqlc.arg(include_read)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_states
+    WHERE post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id) AND post_states.read
+))
+AND (sqlc.narg(feed)::text IS NULL OR feeds.url = sqlc.narg(feed) OR feeds.name = sqlc.narg(feed))
+AND (sqlc.narg(since)::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) < sqlc.narg(until))
+ORDER BY
+    CASE WHEN sqlc.arg(oldest_first)::boolean THEN COALESCE(posts.published_at, posts.created_at) END ASC,
+    CASE WHEN NOT sqlc.arg(oldest_first)::boolean THEN COALESCE(posts.published_at, posts.created_at) END DESC,
+    posts.id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: MarkPostRead :exec
+INSERT INTO post_states (user_id, post_id, read, read_at)
+VALUES ($1, $2, TRUE, $3)
+ON CONFLICT (user_id, post_id) DO UPDATE SET read = TRUE, read_at = EXCLUDED.read_at;
+
+-- name: MarkAllPostsRead :execrows
+INSERT INTO post_states (user_id, post_id, read, read_at)
+SELECT $1, posts.id, TRUE, $2 FROM posts WHERE posts.feed_id IN(
     SELECT feed_id FROM feed_follows
     WHERE user_id = $1
-) ORDER BY COALESCE(posts.published_at, posts.created_at) DESC LIMIT $2;
+)
+ON CONFLICT (user_id, post_id) DO UPDATE SET read = TRUE, read_at = EXCLUDED.read_at
+WHERE NOT post_states.read;
+
+-- name: MarkPostUnread :execrows
+UPDATE post_states SET read = FALSE, read_at = NULL
+WHERE user_id = $1 AND post_id = $2 AND read;
+
+-- name: SavePost :exec
+INSERT INTO saved_posts (user_id, post_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: UnsavePost :execrows
+DELETE FROM saved_posts WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetSavedPostsForUser :many
+SELECT posts.* FROM posts
+INNER JOIN saved_posts ON saved_posts.post_id = posts.id
+WHERE saved_posts.user_id = $1
+ORDER BY saved_posts.created_at DESC;
+
+-- name: SearchPosts :many
+SELECT sqlc.embed(posts), ts_rank(to_tsvector('english', COALESCE(posts.title, '') || ' ' || COALESCE(posts.description, '')), websearch_to_tsquery('english', sqlc.arg(query))) AS rank
+FROM posts
+WHERE to_tsvector('english', COALESCE(posts.title, '') || ' ' || COALESCE(posts.description, '')) @@ websearch_to_tsquery('english', sqlc.arg(query))
+AND (sqlc.arg(all_feeds)::boolean OR posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = sqlc.arg(user_id)
+))
+ORDER BY rank DESC, COALESCE(posts.published_at, posts.created_at) DESC
+LIMIT sqlc.arg('limit');
+
+-- name: GetItemsForUser :many
+SELECT posts.*, feeds.serial_id AS feed_serial_id,
+    EXISTS (
+        SELECT 1 FROM post_states
+        WHERE post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id) AND post_states.read
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = sqlc.arg(user_id)
+    ) AS is_saved
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = sqlc.arg(user_id)
+)
+AND posts.serial_id > sqlc.arg(since_id)::bigint
+AND (sqlc.arg(max_id)::bigint = 0 OR posts.serial_id < sqlc.arg(max_id)::bigint)
+AND (cardinality(sqlc.arg(with_ids)::bigint[]) = 0 OR posts.serial_id = ANY(sqlc.arg(with_ids)::bigint[]))
+ORDER BY
+    CASE WHEN sqlc.arg(max_id)::bigint = 0 THEN posts.serial_id END ASC,
+    posts.serial_id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: CountPostsForUser :one
+SELECT COUNT(*) FROM posts WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = $1
+);
+
+-- name: GetUnreadPostSerialIds :many
+SELECT posts.serial_id FROM posts WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = sqlc.arg(user_id)
+) AND NOT EXISTS (
+    SELECT 1 FROM post_states
+    WHERE post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id) AND post_states.read
+) ORDER BY posts.serial_id;
+
+-- name: GetSavedPostSerialIds :many
+SELECT posts.serial_id FROM posts
+INNER JOIN saved_posts ON saved_posts.post_id = posts.id
+WHERE saved_posts.user_id = $1
+ORDER BY posts.serial_id;
+
+-- name: GetPostIdBySerialId :one
+SELECT id FROM posts WHERE serial_id = $1;
+
+-- name: MarkFeedPostsRead :execrows
+INSERT INTO post_states (user_id, post_id, read, read_at)
+SELECT sqlc.arg(user_id), posts.id, TRUE, sqlc.arg(read_at) FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = sqlc.arg(user_id)
+)
+AND (sqlc.arg(feed_serial_id)::bigint = 0 OR feeds.serial_id = sqlc.arg(feed_serial_id)::bigint)
+AND COALESCE(posts.published_at, posts.created_at) < sqlc.arg(before)::timestamp
+ON CONFLICT (user_id, post_id) DO UPDATE SET read = TRUE, read_at = EXCLUDED.read_at
+WHERE NOT post_states.read;
+
+-- name: GetStreamItemsForUser :many
+SELECT posts.*, feeds.url AS feed_url, feeds.name AS feed_name, feeds.site_url AS feed_site_url,
+    EXISTS (
+        SELECT 1 FROM post_states
+        WHERE post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id) AND post_states.read
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM saved_posts
+        WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = sqlc.arg(user_id)
+    ) AS is_saved
+FROM posts
+INNER JOIN feeds ON posts.feed_id = feeds.id
+WHERE posts.feed_id IN(
+    SELECT feed_id FROM feed_follows
+    WHERE user_id = sqlc.arg(user_id)
+) AND (sqlc.arg(include_read)::boolean OR NOT EXISTS (
+    SELECT 1 FROM post_states
+    WHERE post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id) AND post_states.read
+))
+AND (sqlc.narg(feed_url)::text IS NULL OR feeds.url = sqlc.narg(feed_url))
+AND (NOT sqlc.arg(saved_only)::boolean OR EXISTS (
+    SELECT 1 FROM saved_posts
+    WHERE saved_posts.post_id = posts.id AND saved_posts.user_id = sqlc.arg(user_id)
+))
+AND (NOT sqlc.arg(read_only)::boolean OR EXISTS (
+    SELECT 1 FROM post_states
+    WHERE post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id) AND post_states.read
+))
+AND (sqlc.narg(since)::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) >= sqlc.narg(since))
+AND (sqlc.narg(until)::timestamp IS NULL OR COALESCE(posts.published_at, posts.created_at) < sqlc.narg(until))
+AND (cardinality(sqlc.arg(with_ids)::bigint[]) = 0 OR posts.serial_id = ANY(sqlc.arg(with_ids)::bigint[]))
+ORDER BY
+    CASE WHEN sqlc.arg(oldest_first)::boolean THEN COALESCE(posts.published_at, posts.created_at) END ASC,
+    CASE WHEN NOT sqlc.arg(oldest_first)::boolean THEN COALESCE(posts.published_at, posts.created_at) END DESC,
+    posts.serial_id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
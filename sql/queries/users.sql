@@ -15,4 +15,7 @@ SELECT * FROM users WHERE name = $1;
 DELETE FROM users;
 
 -- name: GetUsers :many
-SELECT * FROM users;
\ No newline at end of file
+SELECT * FROM users;
+
+-- name: ListUsers :many
+SELECT * FROM users ORDER BY name LIMIT $1 OFFSET $2;
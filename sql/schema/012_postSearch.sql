@@ -0,0 +1,9 @@
+-- +goose Up
+-- search queries have to use the same expression for the index to be used
+CREATE INDEX posts_search_idx ON posts USING GIN (
+    to_tsvector('english', COALESCE(title, '') || ' ' || COALESCE(description, ''))
+);
+
+
+-- +goose Down
+DROP INDEX posts_search_idx;
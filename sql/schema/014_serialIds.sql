@@ -0,0 +1,8 @@
+-- +goose Up
+ALTER TABLE feeds ADD COLUMN serial_id BIGSERIAL UNIQUE;
+ALTER TABLE posts ADD COLUMN serial_id BIGSERIAL UNIQUE;
+
+
+-- +goose Down
+ALTER TABLE posts DROP COLUMN serial_id;
+ALTER TABLE feeds DROP COLUMN serial_id;
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE api_tokens ADD COLUMN fever_key TEXT UNIQUE;
+
+
+-- +goose Down
+ALTER TABLE api_tokens DROP COLUMN fever_key;
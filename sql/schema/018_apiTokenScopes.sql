@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE api_tokens ADD COLUMN scope TEXT NOT NULL DEFAULT 'full';
+
+
+-- +goose Down
+ALTER TABLE api_tokens DROP COLUMN scope;
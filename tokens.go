@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/saifullah605/Gator/internal/database"
+)
+
+const tokenPrefix = "gator_"
+
+// a full token can use the whole api, a feed token can only load the merged feed, it ends up in feed reader urls
+const (
+	tokenScopeFull = "full"
+	tokenScopeFeed = "feed"
+)
+
+// newAPIToken returns a random token, only its hash is stored
+func newAPIToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return tokenPrefix + hex.EncodeToString(secret), nil
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// feverKey is the api_key fever clients send, md5 of "username:password" with the token as the password, it is a credential and stored hashed like the token
+func feverKey(userName, token string) string {
+	sum := md5.Sum([]byte(userName + ":" + token))
+	return hex.EncodeToString(sum[:])
+}
+
+func handlerToken(s *state, cmd command, user database.User) error {
+	if len(cmd.arguments) == 0 {
+		return fmt.Errorf("usage: token create [--feed] [name] | token revoke <id|name> | token list")
+	}
+
+	switch cmd.arguments[0] {
+	case "create":
+		return createToken(s, cmd.arguments[1:], cmd.boolFlag("feed"), user)
+	case "revoke":
+		return revokeToken(s, cmd.arguments[1:], user)
+	case "list":
+		return listTokens(s, user)
+	default:
+		return fmt.Errorf("unknown token action %v, use create, revoke or list", cmd.arguments[0])
+	}
+}
+
+func createToken(s *state, arguments []string, feedOnly bool, user database.User) error {
+	name := "default"
+	if len(arguments) != 0 {
+		name = arguments[0]
+	}
+
+	token, err := newAPIToken()
+	if err != nil {
+		return fmt.Errorf("cannot generate token, error: %v", err)
+	}
+
+	// fever clients get full access, so only full tokens have a fever key
+	scope := tokenScopeFull
+	fever := sql.NullString{String: hashAPIToken(feverKey(user.Name, token)), Valid: true}
+	if feedOnly {
+		scope = tokenScopeFeed
+		fever = sql.NullString{}
+	}
+
+	created, err := s.db.CreateApiToken(context.Background(), database.CreateApiTokenParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		Name:      name,
+		TokenHash: hashAPIToken(token),
+		FeverKey:  fever,
+		Scope:     scope,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create token, error: %v", err)
+	}
+
+	fmt.Printf("created %v token %v (%v) for %v\n", created.Scope, created.Name, created.ID, user.Name)
+	fmt.Println("store it now, it cannot be shown again:")
+	fmt.Println(token)
+	return nil
+}
+
+func revokeToken(s *state, arguments []string, user database.User) error {
+	if len(arguments) == 0 {
+		return fmt.Errorf("need the id or name of the token")
+	}
+
+	revoked, err := s.db.RevokeApiToken(context.Background(), database.RevokeApiTokenParams{
+		UserID: user.ID,
+		Token:  arguments[0],
+	})
+	if err != nil {
+		return fmt.Errorf("cannot revoke token, error: %v", err)
+	}
+	if revoked == 0 {
+		return fmt.Errorf("no token %v for %v", arguments[0], user.Name)
+	}
+
+	fmt.Printf("revoked %v token(s)\n", revoked)
+	return nil
+}
+
+func listTokens(s *state, user database.User) error {
+	tokens, err := s.db.GetApiTokensForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("cannot get tokens, error: %v", err)
+	}
+
+	if len(tokens) == 0 {
+		fmt.Println("no tokens, create one with token create")
+		return nil
+	}
+
+	for _, token := range tokens {
+		fmt.Printf("* %v %v (%v), created %v, last used %v\n", token.ID, token.Name, token.Scope, token.CreatedAt.Local().Format(time.DateTime), formatNullTime(token.LastUsedAt))
+	}
+
+	return nil
+}